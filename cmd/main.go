@@ -12,6 +12,7 @@ import (
 	"github.com/miu200521358/walk/pkg/declarative"
 	"github.com/miu200521358/walk/pkg/walk"
 
+	"github.com/miu200521358/mu_motion_viewer/pkg/adapter/mgateway"
 	"github.com/miu200521358/mu_motion_viewer/pkg/infra/controller/ui"
 	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/minteractor"
 
@@ -44,7 +45,7 @@ var appI18nFiles embed.FS
 
 // main はmu_motion_viewerを起動する。
 func main() {
-	initialMotionPath := app.FindInitialPath(os.Args, ".vmd", ".vpd")
+	initialMotionPath := app.FindInitialPath(os.Args, ".vmd", ".vpd", ".csv")
 
 	app.Run(app.RunOptions{
 		ViewerCount: 1,
@@ -59,8 +60,10 @@ func main() {
 		BuildTabPages: func(widgets *controller.MWidgets, baseServices base.IBaseServices, audioPlayer audio_api.IAudioPlayer) []declarative.TabPage {
 			viewerUsecase := minteractor.NewMotionViewerUsecase(minteractor.MotionViewerUsecaseDeps{
 				ModelReader:  io_model.NewModelRepository(),
-				MotionReader: io_motion.NewVmdVpdRepository(),
+				MotionReader: mgateway.NewMotionFileReader(io_motion.NewVmdVpdRepository(), mgateway.NewCsvRepository()),
 				MotionWriter: vmd.NewVmdRepository(),
+				PoseWriter:   mgateway.NewVpdWriter(),
+				CsvWriter:    mgateway.NewCsvRepository(),
 			})
 			return ui.NewTabPages(widgets, baseServices, initialMotionPath, audioPlayer, viewerUsecase)
 		},
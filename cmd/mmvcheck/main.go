@@ -0,0 +1,326 @@
+// 指示: miu200521358
+// mmvcheck はモデルとモーションのOK/NG判定をGUIなしで行うコマンド。
+// -matrix を指定した場合は、複数のモデル/モーション(フォルダ可)の全組を判定してCSVに保存する。
+// -bake-ik を指定した場合は、IKを焼き込んでIKをOFFにしたモーションも保存する。
+//
+// JSONレポートのツールバージョンは、GUIと同じ cmd/app/app_config.json の Version を
+// go build -ldflags "-X main.version=<Version>" で埋め込む。
+//
+// 終了コード: 0 = 全てOK, 1 = NGあり(反映されないIK切替を含む), 2 = 読み込み失敗または引数不正
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/miu200521358/mlib_go/pkg/adapter/io_model"
+	"github.com/miu200521358/mlib_go/pkg/adapter/io_motion"
+	"github.com/miu200521358/mlib_go/pkg/adapter/io_motion/vmd"
+
+	"github.com/miu200521358/mu_motion_viewer/pkg/adapter/mgateway"
+	"github.com/miu200521358/mu_motion_viewer/pkg/adapter/mpresenter/report"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/minteractor"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// 終了コード一覧。
+const (
+	exitOk        = 0
+	exitNg        = 1
+	exitLoadError = 2
+)
+
+const toolName = "mmvcheck"
+
+// version はビルド時に -ldflags で埋め込むツールのバージョン。
+var version string
+
+// pathList は複数指定可能なパス引数を表す。
+type pathList []string
+
+// String は引数の表示文字列を返す。
+func (p *pathList) String() string {
+	return strings.Join(*p, ",")
+}
+
+// Set は引数を追加する。
+func (p *pathList) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// main はmmvcheckを起動する。
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run は引数を解釈して判定を実行し、終了コードを返す。
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet(toolName, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var modelPaths pathList
+	flags.Var(&modelPaths, "model", "PMXモデルのパス。-matrix 指定時は複数指定・フォルダ指定可")
+	var motionPaths pathList
+	flags.Var(&motionPaths, "motion", "VMD/VPD/CSVモーションのパス。-matrix 指定時は複数指定・フォルダ指定可")
+	matrixPath := flags.String("matrix", "", "一括判定結果を保存するCSVのパス")
+	format := flags.String("format", "text", "出力形式 (text|json)")
+	matchEnglish := flags.Bool("english", false, "モデルの英名とも照合する")
+	var aliasPaths pathList
+	flags.Var(&aliasPaths, "alias", "別名辞書(JSON/CSV)のパス。複数指定可")
+	var profilePaths pathList
+	flags.Var(&profilePaths, "profile", "ボーンプロファイル(JSON)のパス。複数指定可")
+	weightsPath := flags.String("weights", "", "互換スコアの重み付け(JSON)のパス")
+	bakeIkPath := flags.String("bake-ik", "", "IKをFK回転に焼き込んだモーションを保存するVMDのパス")
+	bakeEveryFrame := flags.Bool("bake-every-frame", false, "IKの焼き込みをキーフレームだけでなく全フレームで行う")
+	if err := flags.Parse(args); err != nil {
+		return exitLoadError
+	}
+	if len(modelPaths) == 0 || len(motionPaths) == 0 {
+		fmt.Fprintln(stderr, "-model と -motion を指定してください")
+		flags.Usage()
+		return exitLoadError
+	}
+	if *matrixPath == "" && (len(modelPaths) > 1 || len(motionPaths) > 1) {
+		fmt.Fprintln(stderr, "複数のモデル/モーションを判定する場合は -matrix を指定してください")
+		return exitLoadError
+	}
+	if *matrixPath != "" && *bakeIkPath != "" {
+		fmt.Fprintln(stderr, "-bake-ik は -matrix と同時に指定できません")
+		return exitLoadError
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "未対応の出力形式です: %s\n", *format)
+		return exitLoadError
+	}
+
+	options, err := buildCheckOptions(aliasPaths, profilePaths, *matchEnglish)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return exitLoadError
+	}
+
+	weights := minteractor.DefaultScoreWeights()
+	if *weightsPath != "" {
+		weights, err = minteractor.LoadScoreWeights(*weightsPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "重み付けの読み込みに失敗しました: %s\n", err.Error())
+			return exitLoadError
+		}
+	}
+
+	motionWriter := vmd.NewVmdRepository()
+	viewerUsecase := minteractor.NewMotionViewerUsecase(minteractor.MotionViewerUsecaseDeps{
+		ModelReader:  io_model.NewModelRepository(),
+		MotionReader: mgateway.NewMotionFileReader(io_motion.NewVmdVpdRepository(), mgateway.NewCsvRepository()),
+		MotionWriter: motionWriter,
+	})
+	if *matrixPath != "" {
+		return runMatrix(viewerUsecase, modelPaths, motionPaths, options, weights, *matrixPath, stdout, stderr)
+	}
+
+	modelPath := modelPaths[0]
+	motionPath := motionPaths[0]
+	modelResult, err := viewerUsecase.LoadModel(nil, modelPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "モデルの読み込みに失敗しました: %s\n", err.Error())
+		return exitLoadError
+	}
+	motionResult, err := viewerUsecase.LoadMotion(nil, motionPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "モーションの読み込みに失敗しました: %s\n", err.Error())
+		return exitLoadError
+	}
+	modelData := minteractor.ExtractModelData(modelResult)
+	motionData, _ := minteractor.ExtractMotionData(motionResult)
+
+	result, err := minteractor.CheckExistsWithOptions(modelData, motionData, options)
+	if err != nil {
+		fmt.Fprintf(stderr, "OK/NG判定に失敗しました: %s\n", err.Error())
+		return exitLoadError
+	}
+
+	if *format == "json" {
+		built, err := report.Build(report.Source{
+			ModelPath:   modelPath,
+			MotionPath:  motionPath,
+			ToolName:    toolName,
+			ToolVersion: version,
+			Result:      result,
+			Weights:     &weights,
+		})
+		if err == nil {
+			err = report.Write(stdout, report.FormatJSON, built)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "結果の出力に失敗しました: %s\n", err.Error())
+			return exitLoadError
+		}
+	} else {
+		writeText(stdout, modelPath, motionPath, result, weights)
+	}
+
+	if *bakeIkPath != "" {
+		baked, bakeResult, err := minteractor.BakeIk(modelData, motionData, minteractor.IkBakeOptions{EveryFrame: *bakeEveryFrame})
+		if err == nil && baked != nil {
+			err = motionWriter.Save(*bakeIkPath, baked, moutput.SaveOptions{})
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "IKの焼き込みに失敗しました: %s\n", err.Error())
+			return exitLoadError
+		}
+		// JSON出力を壊さないよう、焼き込みの結果は標準エラーに出力する。
+		writeIkBakeResult(stderr, *bakeIkPath, bakeResult)
+	}
+
+	if len(result.NgBones) > 0 || len(result.NgMorphs) > 0 || hasInvalidIk(result.IkBones) {
+		return exitNg
+	}
+	return exitOk
+}
+
+// writeIkBakeResult はIK焼き込みの結果を出力する。
+func writeIkBakeResult(w io.Writer, path string, result *minteractor.IkBakeResult) {
+	if result == nil {
+		return
+	}
+	fmt.Fprintf(w, "IK焼き込み: %s\n", path)
+	fmt.Fprintf(w, "  フレーム数: %d\n", result.FrameCount)
+	fmt.Fprintf(w, "  ボーン: %s\n", strings.Join(result.BakedBones, ", "))
+	fmt.Fprintf(w, "  最大誤差: %.4f\n", result.MaxError)
+}
+
+// writeAmbiguousLines は曖昧な切り詰め一致を候補付きで出力する。
+func writeAmbiguousLines(w io.Writer, label string, entries []minteractor.AmbiguousName) {
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s: %s => %s [%d keys]\n", label, entry.Name, strings.Join(entry.Candidates, " | "), entry.KeyCount)
+	}
+}
+
+// hasInvalidIk はモデルに反映されないIK切替があるか判定する。
+func hasInvalidIk(entries []minteractor.IkFrameEntry) bool {
+	for _, entry := range entries {
+		if !entry.IsOk() {
+			return true
+		}
+	}
+	return false
+}
+
+// runMatrix は全てのモデルとモーションの組を判定し、結果をCSVに保存する。
+func runMatrix(viewerUsecase *minteractor.MotionViewerUsecase, modelPaths []string, motionPaths []string, options minteractor.CheckOptions, weights minteractor.ScoreWeights, matrixPath string, stdout io.Writer, stderr io.Writer) int {
+	matrix, err := viewerUsecase.CheckMatrix(minteractor.CompatibilityMatrixRequest{
+		ModelPaths:  modelPaths,
+		MotionPaths: motionPaths,
+		Options:     options,
+		Weights:     &weights,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "一括判定に失敗しました: %s\n", err.Error())
+		return exitLoadError
+	}
+	if err := report.WriteMatrixFile(matrixPath, matrix); err != nil {
+		fmt.Fprintf(stderr, "結果の出力に失敗しました: %s\n", err.Error())
+		return exitLoadError
+	}
+
+	exitCode := exitOk
+	for i, modelPath := range matrix.ModelPaths {
+		for j, motionPath := range matrix.MotionPaths {
+			cell := matrix.Cells[i][j]
+			if cell.Err != nil {
+				fmt.Fprintf(stdout, "%s x %s: ERROR %s\n", modelPath, motionPath, cell.Err.Error())
+				exitCode = exitLoadError
+				continue
+			}
+			fmt.Fprintf(stdout, "%s x %s: score %.1f / %.1f%% (OK %d / NG %d)\n",
+				modelPath, motionPath, cell.Score, cell.OkPercent(), cell.OkCount(), cell.NgCount())
+			if cell.NgCount() > 0 && exitCode == exitOk {
+				exitCode = exitNg
+			}
+		}
+	}
+	return exitCode
+}
+
+// buildCheckOptions は引数から判定オプションを組み立てる。
+func buildCheckOptions(aliasPaths []string, profilePaths []string, matchEnglish bool) (minteractor.CheckOptions, error) {
+	options := minteractor.CheckOptions{
+		MatchEnglishName: matchEnglish,
+		Profiles:         minteractor.BuiltinBoneProfiles(),
+	}
+	if len(aliasPaths) > 0 {
+		options.Aliases = minteractor.NewAliasDictionary()
+		for _, path := range aliasPaths {
+			dict, err := minteractor.LoadAliasDictionary(path)
+			if err != nil {
+				return options, fmt.Errorf("別名辞書の読み込みに失敗しました: %w", err)
+			}
+			options.Aliases.Merge(dict)
+		}
+	}
+	for _, path := range profilePaths {
+		profiles, err := minteractor.LoadBoneProfiles(path)
+		if err != nil {
+			return options, fmt.Errorf("ボーンプロファイルの読み込みに失敗しました: %w", err)
+		}
+		options.Profiles = append(options.Profiles, profiles...)
+	}
+	return options, nil
+}
+
+// writeText は判定結果を人が読む形式で出力する。
+func writeText(w io.Writer, modelPath string, motionPath string, result minteractor.CheckResult, weights minteractor.ScoreWeights) {
+	fmt.Fprintf(w, "model:  %s\n", modelPath)
+	fmt.Fprintf(w, "motion: %s\n", motionPath)
+	fmt.Fprintf(w, "OK bones: %d, OK morphs: %d, NG bones: %d, NG morphs: %d\n",
+		len(result.OkBones), len(result.OkMorphs), len(result.NgBones), len(result.NgMorphs))
+	score := minteractor.ScoreCompatibility(result, weights)
+	fmt.Fprintf(w, "score: %.1f\n", score.Score)
+	for _, contributor := range score.Contributors {
+		fmt.Fprintf(w, "  -%.1f %s %s (%s)\n", contributor.Penalty, contributor.Kind, contributor.Name, contributor.Tier)
+	}
+	writeNgLines(w, "NG bone", result.NgBones, result.NgBoneSuggestions)
+	writeNgLines(w, "NG morph", result.NgMorphs, result.NgMorphSuggestions)
+	writeMappedLines(w, "MAPPED bone", result.MappedBones)
+	writeMappedLines(w, "MAPPED morph", result.MappedMorphs)
+	writeMappedLines(w, "ENGLISH bone", result.EnglishBones)
+	writeMappedLines(w, "ENGLISH morph", result.EnglishMorphs)
+	writeMappedLines(w, "TRUNCATED bone", result.TruncatedBones)
+	writeMappedLines(w, "TRUNCATED morph", result.TruncatedMorphs)
+	writeAmbiguousLines(w, "AMBIGUOUS bone", result.AmbiguousBones)
+	writeAmbiguousLines(w, "AMBIGUOUS morph", result.AmbiguousMorphs)
+	for _, entry := range result.ProfileAudit {
+		fmt.Fprintf(w, "MISSING %s (%s): %s [%d keys]\n", entry.Name, entry.Profile, entry.Effect, entry.KeyCount)
+	}
+	for _, entry := range result.LipSyncAudit {
+		fmt.Fprintf(w, "LIPSYNC %s: %s [%d keys]\n", entry.Name, entry.Effect, entry.KeyCount)
+	}
+	for _, entry := range result.IkBones {
+		label := "IK"
+		if !entry.IsOk() {
+			label = "NG IK"
+		}
+		fmt.Fprintf(w, "%s %s: %s [%s]\n", label, entry.Name, entry.Status, minteractor.FormatIkToggles(entry.Toggles))
+	}
+}
+
+// writeNgLines はNGトラックを候補付きで出力する。
+func writeNgLines(w io.Writer, label string, entries []minteractor.TrackEntry, suggestions map[string][]minteractor.NameSuggestion) {
+	for _, entry := range entries {
+		if suggestion, ok := minteractor.TopSuggestion(suggestions, entry.Name); ok {
+			fmt.Fprintf(w, "%s: %s [%d keys] -> %s?\n", label, entry.Name, entry.KeyCount, suggestion.Name)
+			continue
+		}
+		fmt.Fprintf(w, "%s: %s [%d keys]\n", label, entry.Name, entry.KeyCount)
+	}
+}
+
+// writeMappedLines は対応付け結果を出力する。
+func writeMappedLines(w io.Writer, label string, entries []minteractor.MappedName) {
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s: %s => %s [%d keys]\n", label, entry.Name, entry.Target, entry.KeyCount)
+	}
+}
@@ -13,6 +13,7 @@ replace github.com/miu200521358/mlib_go => ../mlib_go_t4
 require (
 	github.com/miu200521358/mlib_go v0.0.0-00010101000000-000000000000
 	github.com/miu200521358/walk v0.0.6
+	golang.org/x/text v0.33.0
 )
 
 require (
@@ -22,7 +23,6 @@ require (
 	github.com/miu200521358/win v0.0.2 // indirect
 	github.com/tiendc/go-deepcopy v1.7.2 // indirect
 	golang.org/x/sys v0.39.0 // indirect
-	golang.org/x/text v0.33.0 // indirect
 	gonum.org/v1/gonum v0.16.0 // indirect
 )
 
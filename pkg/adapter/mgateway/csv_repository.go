@@ -0,0 +1,671 @@
+// 指示: miu200521358
+package mgateway
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mlib_go/pkg/shared/hashable"
+	"golang.org/x/text/encoding/japanese"
+
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// csvSignature はCSVの1行目に書くVMDのシグネチャ。
+const csvSignature = "Vocaloid Motion Data 0002"
+
+// csvExtension はCSVファイルの拡張子。
+const csvExtension = ".csv"
+
+// utf8Bom はExcelなどがUTF-8のCSVの先頭に付けるBOM。
+const utf8Bom = "\ufeff"
+
+// csvSection はCSVの区分を表す。
+type csvSection int
+
+// CSVの区分一覧。各区分は見出し行から始まる。
+const (
+	csvSectionNone csvSection = iota
+	csvSectionBone
+	csvSectionMorph
+	csvSectionCamera
+	csvSectionLight
+	csvSectionShadow
+	csvSectionIk
+)
+
+// csvBoneCurveChannels/csvCameraCurveChannels は補間曲線の列名に使う軸の名前。VMDの補間パラメータの並び順。
+var (
+	csvBoneCurveChannels   = []string{"X", "Y", "Z", "R"}
+	csvCameraCurveChannels = []string{"X", "Y", "Z", "R", "L", "V"}
+)
+
+// 各区分の見出し行。先頭2列で区分を判定する。
+var (
+	csvBoneHeader   = append([]string{"ボーン名", "フレーム", "位置X", "位置Y", "位置Z", "回転X", "回転Y", "回転Z"}, boneCurveHeader()...)
+	csvMorphHeader  = []string{"モーフ名", "フレーム", "値"}
+	csvCameraHeader = append(append([]string{"フレーム", "距離", "位置X", "位置Y", "位置Z", "回転X", "回転Y", "回転Z"},
+		cameraCurveHeader()...), "視野角", "パース")
+	csvLightHeader  = []string{"フレーム", "赤", "緑", "青", "X", "Y", "Z"}
+	csvShadowHeader = []string{"フレーム", "モード", "距離"}
+	csvIkHeader     = []string{"フレーム", "表示", "IK名", "有効"}
+)
+
+// csvSectionHeaders は見出し行の先頭2列と区分の対応。
+var csvSectionHeaders = map[[2]string]csvSection{
+	{csvBoneHeader[0], csvBoneHeader[1]}:     csvSectionBone,
+	{"表情名", "フレーム"}:                          csvSectionMorph,
+	{csvMorphHeader[0], csvMorphHeader[1]}:   csvSectionMorph,
+	{csvCameraHeader[0], csvCameraHeader[1]}: csvSectionCamera,
+	{csvLightHeader[0], csvLightHeader[1]}:   csvSectionLight,
+	{csvShadowHeader[0], csvShadowHeader[1]}: csvSectionShadow,
+	{csvIkHeader[0], csvIkHeader[1]}:         csvSectionIk,
+}
+
+// CsvRepository はモーションのキーフレームを人が編集できるCSVとして読み書きする。
+// 列の並びはMMDで広く使われているVMD⇔CSV変換ツールに合わせ、回転は度数法のオイラー角、
+// 補間曲線はVMDと同じ並びの0～127の整数で書く。文字コードはShift-JISで、読み込み時はUTF-8も受け付ける。
+type CsvRepository struct{}
+
+// NewCsvRepository はCSVの読み書きリポジトリを生成する。
+func NewCsvRepository() *CsvRepository {
+	return &CsvRepository{}
+}
+
+// CanLoad は拡張子がCSVで、1行目がVMDのシグネチャのファイルか判定する。
+func (r *CsvRepository) CanLoad(path string) bool {
+	if !strings.EqualFold(filepath.Ext(path), csvExtension) {
+		return false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimPrefix(line, utf8Bom), csvSignature)
+}
+
+// Load はCSVファイルからモーションを読み込む。
+func (r *CsvRepository) Load(path string) (hashable.IHashable, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text, err := decodeCsv(content)
+	if err != nil {
+		return nil, err
+	}
+	motionData, err := ReadCsv(strings.NewReader(text), path)
+	if err != nil {
+		return nil, err
+	}
+	motionData.UpdateHash()
+	return motionData, nil
+}
+
+// InferName はファイル名から拡張子を除いた名前を返す。
+func (r *CsvRepository) InferName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Save はモーションをCSVファイルに保存する。
+func (r *CsvRepository) Save(path string, data hashable.IHashable, _ moutput.SaveOptions) error {
+	motionData, ok := data.(*motion.VmdMotion)
+	if !ok || motionData == nil {
+		return fmt.Errorf("CSVに保存できるのはモーションだけです")
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteCsv(file, motionData); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WriteCsv はモーションの全キーフレームをShift-JISのCSVで書き出す。
+// キーのない区分も見出し行は書き出す。
+func WriteCsv(w io.Writer, motionData *motion.VmdMotion) error {
+	if motionData == nil {
+		return fmt.Errorf("モーションがありません")
+	}
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	writer.UseCRLF = true
+	rows := [][]string{
+		{csvSignature, "0"},
+		{motionData.Name(), ""},
+		csvBoneHeader,
+	}
+	if motionData.BoneFrames != nil {
+		for _, name := range motionData.BoneFrames.Names() {
+			frames := motionData.BoneFrames.Get(name)
+			if frames == nil {
+				continue
+			}
+			frames.ForEach(func(frame motion.Frame, value *motion.BoneFrame) bool {
+				if value != nil {
+					rows = append(rows, boneRow(name, frame, value))
+				}
+				return true
+			})
+		}
+	}
+	rows = append(rows, csvMorphHeader)
+	if motionData.MorphFrames != nil {
+		for _, name := range motionData.MorphFrames.Names() {
+			frames := motionData.MorphFrames.Get(name)
+			if frames == nil {
+				continue
+			}
+			frames.ForEach(func(frame motion.Frame, value *motion.MorphFrame) bool {
+				if value != nil {
+					rows = append(rows, []string{name, formatCsvFrame(frame), formatCsvFloat(value.Ratio)})
+				}
+				return true
+			})
+		}
+	}
+	rows = append(rows, csvCameraHeader)
+	if motionData.CameraFrames != nil {
+		motionData.CameraFrames.ForEach(func(frame motion.Frame, value *motion.CameraFrame) bool {
+			if value != nil {
+				rows = append(rows, cameraRow(frame, value))
+			}
+			return true
+		})
+	}
+	rows = append(rows, csvLightHeader)
+	if motionData.LightFrames != nil {
+		motionData.LightFrames.ForEach(func(frame motion.Frame, value *motion.LightFrame) bool {
+			if value != nil {
+				row := []string{formatCsvFrame(frame)}
+				row = append(row, formatCsvVec3(value.Color)...)
+				rows = append(rows, append(row, formatCsvVec3(value.Position)...))
+			}
+			return true
+		})
+	}
+	rows = append(rows, csvShadowHeader)
+	if motionData.ShadowFrames != nil {
+		motionData.ShadowFrames.ForEach(func(frame motion.Frame, value *motion.ShadowFrame) bool {
+			if value != nil {
+				rows = append(rows, []string{formatCsvFrame(frame), strconv.Itoa(value.ShadowMode), formatCsvFloat(value.Distance)})
+			}
+			return true
+		})
+	}
+	rows = append(rows, csvIkHeader)
+	if motionData.IkFrames != nil {
+		motionData.IkFrames.ForEach(func(frame motion.Frame, value *motion.IkFrame) bool {
+			if value == nil {
+				return true
+			}
+			row := []string{formatCsvFrame(frame), formatCsvFlag(value.Visible)}
+			for _, ik := range value.IkList {
+				if ik != nil {
+					row = append(row, ik.BoneName, formatCsvFlag(ik.Enabled))
+				}
+			}
+			rows = append(rows, row)
+			return true
+		})
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	encoded, err := japanese.ShiftJIS.NewEncoder().String(buffer.String())
+	if err != nil {
+		return fmt.Errorf("Shift-JISに変換できない名前があります: %w", err)
+	}
+	_, err = io.WriteString(w, encoded)
+	return err
+}
+
+// ReadCsv はCSVを読み込み、path を保存元とするモーションを作る。
+// 1行目はVMDのシグネチャ、2行目はモデル名とし、以降は見出し行で区分を切り替えながらキーフレームを読む。
+func ReadCsv(r io.Reader, path string) (*motion.VmdMotion, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	motionData := motion.NewVmdMotion(path)
+	motionData.BoneFrames = motion.NewBoneFrames()
+	motionData.MorphFrames = motion.NewMorphFrames()
+	motionData.CameraFrames = motion.NewCameraFrames()
+	motionData.LightFrames = motion.NewLightFrames()
+	motionData.ShadowFrames = motion.NewShadowFrames()
+	motionData.IkFrames = motion.NewIkFrames()
+	boneFrames := make(map[string]*motion.BoneNameFrames)
+	boneNames := make([]string, 0)
+	morphFrames := make(map[string]*motion.MorphNameFrames)
+	morphNames := make([]string, 0)
+
+	section := csvSectionNone
+	for index := 0; ; index++ {
+		cells, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		cells = trimCsvCells(cells)
+		if len(cells) == 0 {
+			continue
+		}
+		if index == 0 {
+			if !strings.HasPrefix(strings.TrimPrefix(cells[0], utf8Bom), csvSignature) {
+				return nil, fmt.Errorf("%d行目: VMDのCSVではありません", line)
+			}
+			continue
+		}
+		if len(cells) >= 2 {
+			if next, ok := csvSectionHeaders[[2]string{cells[0], cells[1]}]; ok {
+				section = next
+				continue
+			}
+		}
+		if section == csvSectionNone {
+			// 見出し行より前の行はモデル名とする。
+			if index == 1 {
+				motionData.SetName(cells[0])
+			}
+			continue
+		}
+
+		row := &csvRow{cells: cells, line: line}
+		switch section {
+		case csvSectionBone:
+			if !row.require(len(csvBoneHeader)) {
+				break
+			}
+			name := cells[0]
+			value := row.boneFrame()
+			if row.err != nil {
+				break
+			}
+			frames, ok := boneFrames[name]
+			if !ok {
+				frames = motion.NewBoneNameFrames(name)
+				boneFrames[name] = frames
+				boneNames = append(boneNames, name)
+			}
+			frames.Append(value)
+		case csvSectionMorph:
+			if !row.require(len(csvMorphHeader)) {
+				break
+			}
+			name := cells[0]
+			value := motion.NewMorphFrame(row.frame(1))
+			value.Ratio = row.float(2)
+			if row.err != nil {
+				break
+			}
+			frames, ok := morphFrames[name]
+			if !ok {
+				frames = motion.NewMorphNameFrames(name)
+				morphFrames[name] = frames
+				morphNames = append(morphNames, name)
+			}
+			frames.Append(value)
+		case csvSectionCamera:
+			if row.require(len(csvCameraHeader)) {
+				value := row.cameraFrame()
+				if row.err == nil {
+					motionData.CameraFrames.Append(value)
+				}
+			}
+		case csvSectionLight:
+			if row.require(len(csvLightHeader)) {
+				value := motion.NewLightFrame(row.frame(0))
+				value.Color = row.vec3(1)
+				value.Position = row.vec3(4)
+				if row.err == nil {
+					motionData.LightFrames.Append(value)
+				}
+			}
+		case csvSectionShadow:
+			if row.require(len(csvShadowHeader)) {
+				value := motion.NewShadowFrame(row.frame(0))
+				value.ShadowMode = row.int(1)
+				value.Distance = row.float(2)
+				if row.err == nil {
+					motionData.ShadowFrames.Append(value)
+				}
+			}
+		case csvSectionIk:
+			if row.require(2) {
+				value := row.ikFrame()
+				if row.err == nil {
+					motionData.IkFrames.Append(value)
+				}
+			}
+		}
+		if row.err != nil {
+			return nil, row.err
+		}
+	}
+
+	for _, name := range boneNames {
+		motionData.BoneFrames.Update(boneFrames[name])
+	}
+	for _, name := range morphNames {
+		motionData.MorphFrames.Update(morphFrames[name])
+	}
+	return motionData, nil
+}
+
+// decodeCsv はCSVの内容を文字列にする。UTF-8として正しくない場合はShift-JISとして読む。
+func decodeCsv(content []byte) (string, error) {
+	if utf8.Valid(content) {
+		return strings.TrimPrefix(string(content), utf8Bom), nil
+	}
+	text, err := japanese.ShiftJIS.NewDecoder().String(string(content))
+	if err != nil {
+		return "", fmt.Errorf("CSVの文字コードを判定できません: %w", err)
+	}
+	return text, nil
+}
+
+// trimCsvCells は各列の前後の空白と、表計算ソフトが行末に補う空の列を取り除く。
+func trimCsvCells(cells []string) []string {
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	for len(cells) > 0 && cells[len(cells)-1] == "" {
+		cells = cells[:len(cells)-1]
+	}
+	return cells
+}
+
+// boneRow はボーンキーフレームを1行にする。
+func boneRow(name string, frame motion.Frame, value *motion.BoneFrame) []string {
+	row := []string{name, formatCsvFrame(frame)}
+	row = append(row, formatCsvVec3(value.Position)...)
+	rotation := mmath.NewQuaternion()
+	if value.Rotation != nil {
+		rotation = value.Rotation
+	}
+	row = append(row, formatCsvDegrees(rotation.ToEulerAngles())...)
+	curves := motion.NewBoneCurves()
+	if value.Curves != nil {
+		curves = value.Curves
+	}
+	channels := []*mmath.Curve{curves.TranslateX, curves.TranslateY, curves.TranslateZ, curves.Rotate}
+	// ボーンの補間パラメータはパラメータごとに4軸を並べる（X_x1, Y_x1, Z_x1, R_x1, X_y1, ...）。
+	for param := 0; param < 4; param++ {
+		for _, curve := range channels {
+			row = append(row, strconv.Itoa(curveParam(curve, param)))
+		}
+	}
+	return row
+}
+
+// cameraRow はカメラキーフレームを1行にする。
+func cameraRow(frame motion.Frame, value *motion.CameraFrame) []string {
+	row := []string{formatCsvFrame(frame), formatCsvFloat(value.Distance)}
+	row = append(row, formatCsvVec3(value.Position)...)
+	row = append(row, formatCsvVec3(value.Degrees)...)
+	curves := motion.NewCameraCurves()
+	if value.Curves != nil {
+		curves = value.Curves
+	}
+	channels := []*mmath.Curve{curves.TranslateX, curves.TranslateY, curves.TranslateZ,
+		curves.Rotate, curves.Distance, curves.ViewOfAngle}
+	// カメラの補間パラメータは軸ごとに x1, x2, y1, y2 を並べる。
+	for _, curve := range channels {
+		for _, param := range cameraCurveParams {
+			row = append(row, strconv.Itoa(curveParam(curve, param)))
+		}
+	}
+	return append(row, strconv.Itoa(value.ViewOfAngle), formatCsvFlag(value.IsPerspectiveOff))
+}
+
+// cameraCurveParams はカメラの補間パラメータの並び順（curveParam の番号）。
+var cameraCurveParams = []int{0, 2, 1, 3}
+
+// curveParamNames は curveParam の番号ごとの列名。
+var curveParamNames = []string{"x1", "y1", "x2", "y2"}
+
+// boneCurveHeader はボーンの補間曲線の列名を返す。
+func boneCurveHeader() []string {
+	header := make([]string, 0, len(curveParamNames)*len(csvBoneCurveChannels))
+	for _, param := range curveParamNames {
+		for _, channel := range csvBoneCurveChannels {
+			header = append(header, fmt.Sprintf("【%s_%s】", channel, param))
+		}
+	}
+	return header
+}
+
+// cameraCurveHeader はカメラの補間曲線の列名を返す。
+func cameraCurveHeader() []string {
+	header := make([]string, 0, len(cameraCurveParams)*len(csvCameraCurveChannels))
+	for _, channel := range csvCameraCurveChannels {
+		for _, param := range cameraCurveParams {
+			header = append(header, fmt.Sprintf("【%s_%s】", channel, curveParamNames[param]))
+		}
+	}
+	return header
+}
+
+// curveParam は補間曲線の x1, y1, x2, y2 の順の param 番目の値を返す。nil の場合は線形補間の値を返す。
+func curveParam(curve *mmath.Curve, param int) int {
+	if curve == nil {
+		curve = mmath.NewCurve()
+	}
+	values := [4]float64{curve.Start.X, curve.Start.Y, curve.End.X, curve.End.Y}
+	return int(math.Round(values[param]))
+}
+
+// setCurveParam は補間曲線の x1, y1, x2, y2 の順の param 番目の値を設定する。
+func setCurveParam(curve *mmath.Curve, param int, value float64) {
+	switch param {
+	case 0:
+		curve.Start.X = value
+	case 1:
+		curve.Start.Y = value
+	case 2:
+		curve.End.X = value
+	case 3:
+		curve.End.Y = value
+	}
+}
+
+// formatCsvFrame はフレーム番号を書き出す。
+func formatCsvFrame(frame motion.Frame) string {
+	return formatCsvFloat(float64(frame))
+}
+
+// formatCsvFloat は小数を末尾の0を除いて書き出す。
+func formatCsvFloat(value float64) string {
+	text := strconv.FormatFloat(value, 'f', 6, 64)
+	text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	if text == "-0" {
+		return "0"
+	}
+	return text
+}
+
+// formatCsvVec3 はベクトルをX, Y, Zの3列にする。nil の場合は0とする。
+func formatCsvVec3(value *mmath.Vec3) []string {
+	if value == nil {
+		value = mmath.NewVec3()
+	}
+	return []string{formatCsvFloat(value.X), formatCsvFloat(value.Y), formatCsvFloat(value.Z)}
+}
+
+// formatCsvDegrees はラジアンのオイラー角を度数法の3列にする。
+func formatCsvDegrees(radians *mmath.Vec3) []string {
+	if radians == nil {
+		radians = mmath.NewVec3()
+	}
+	return formatCsvVec3(&mmath.Vec3{
+		X: radians.X * 180 / math.Pi,
+		Y: radians.Y * 180 / math.Pi,
+		Z: radians.Z * 180 / math.Pi,
+	})
+}
+
+// formatCsvFlag は真偽値を1/0で書き出す。
+func formatCsvFlag(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// csvRow はCSVの1行を読み取る。最初のエラーを err に保持し、以降の読み取りは零値を返す。
+type csvRow struct {
+	cells []string
+	line  int
+	err   error
+}
+
+// require は列数が count 以上あるか確認する。
+func (r *csvRow) require(count int) bool {
+	if len(r.cells) < count {
+		r.err = fmt.Errorf("%d行目: 列が足りません（%d列必要です）", r.line, count)
+		return false
+	}
+	return true
+}
+
+// float は index 列目を小数として読む。
+func (r *csvRow) float(index int) float64 {
+	if r.err != nil {
+		return 0
+	}
+	value, err := strconv.ParseFloat(r.cells[index], 64)
+	if err != nil {
+		r.err = fmt.Errorf("%d行目%d列目: 数値ではありません: %s", r.line, index+1, r.cells[index])
+		return 0
+	}
+	return value
+}
+
+// int は index 列目を整数として読む。
+func (r *csvRow) int(index int) int {
+	value := r.float(index)
+	if r.err == nil && value != math.Trunc(value) {
+		r.err = fmt.Errorf("%d行目%d列目: 整数ではありません: %s", r.line, index+1, r.cells[index])
+	}
+	return int(value)
+}
+
+// frame は index 列目をフレーム番号として読む。
+func (r *csvRow) frame(index int) motion.Frame {
+	value := r.float(index)
+	if r.err == nil && value < 0 {
+		r.err = fmt.Errorf("%d行目%d列目: フレームが不正です: %s", r.line, index+1, r.cells[index])
+	}
+	return motion.Frame(value)
+}
+
+// flag は index 列目を1/0の真偽値として読む。
+func (r *csvRow) flag(index int) bool {
+	return r.int(index) != 0
+}
+
+// vec3 は index 列目からの3列をベクトルとして読む。
+func (r *csvRow) vec3(index int) *mmath.Vec3 {
+	return &mmath.Vec3{X: r.float(index), Y: r.float(index + 1), Z: r.float(index + 2)}
+}
+
+// curveValue は index 列目を補間パラメータ(0～127)として読む。
+func (r *csvRow) curveValue(index int) float64 {
+	value := r.int(index)
+	if r.err == nil && (value < 0 || value > 127) {
+		r.err = fmt.Errorf("%d行目%d列目: 補間パラメータは0～127です: %s", r.line, index+1, r.cells[index])
+	}
+	return float64(value)
+}
+
+// boneFrame はボーンキーフレームの行を読む。
+func (r *csvRow) boneFrame() *motion.BoneFrame {
+	value := motion.NewBoneFrame(r.frame(1))
+	value.Position = r.vec3(2)
+	degrees := r.vec3(5)
+	value.Rotation = mmath.NewQuaternionFromEulerAngles(
+		degrees.X*math.Pi/180, degrees.Y*math.Pi/180, degrees.Z*math.Pi/180)
+	value.Curves = motion.NewBoneCurves()
+	channels := []*mmath.Curve{mmath.NewCurve(), mmath.NewCurve(), mmath.NewCurve(), mmath.NewCurve()}
+	column := 8
+	for param := 0; param < 4; param++ {
+		for _, curve := range channels {
+			setCurveParam(curve, param, r.curveValue(column))
+			column++
+		}
+	}
+	value.Curves.TranslateX = channels[0]
+	value.Curves.TranslateY = channels[1]
+	value.Curves.TranslateZ = channels[2]
+	value.Curves.Rotate = channels[3]
+	return value
+}
+
+// cameraFrame はカメラキーフレームの行を読む。
+func (r *csvRow) cameraFrame() *motion.CameraFrame {
+	value := motion.NewCameraFrame(r.frame(0))
+	value.Distance = r.float(1)
+	value.Position = r.vec3(2)
+	value.Degrees = r.vec3(5)
+	value.Curves = motion.NewCameraCurves()
+	channels := make([]*mmath.Curve, len(csvCameraCurveChannels))
+	column := 8
+	for i := range channels {
+		channels[i] = mmath.NewCurve()
+		for _, param := range cameraCurveParams {
+			setCurveParam(channels[i], param, r.curveValue(column))
+			column++
+		}
+	}
+	value.Curves.TranslateX = channels[0]
+	value.Curves.TranslateY = channels[1]
+	value.Curves.TranslateZ = channels[2]
+	value.Curves.Rotate = channels[3]
+	value.Curves.Distance = channels[4]
+	value.Curves.ViewOfAngle = channels[5]
+	value.ViewOfAngle = r.int(column)
+	value.IsPerspectiveOff = r.flag(column + 1)
+	return value
+}
+
+// ikFrame はIKキーフレームの行を読む。3列目以降はIK名と有効(1/0)の組を並べる。
+func (r *csvRow) ikFrame() *motion.IkFrame {
+	value := motion.NewIkFrame(r.frame(0))
+	value.Visible = r.flag(1)
+	if r.err == nil && len(r.cells)%2 != 0 {
+		r.err = fmt.Errorf("%d行目: IK名と有効の組がそろっていません", r.line)
+		return value
+	}
+	value.IkList = make([]*motion.IkEnabledFrame, 0, (len(r.cells)-2)/2)
+	for i := 2; i+1 < len(r.cells); i += 2 {
+		if r.cells[i] == "" {
+			continue
+		}
+		value.IkList = append(value.IkList, motion.NewIkEnabledFrame(r.cells[i], r.flag(i+1)))
+	}
+	return value
+}
@@ -0,0 +1,177 @@
+// 指示: miu200521358
+package mgateway
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/minteractor"
+)
+
+// newTestCurve は x1, y1, x2, y2 がすべて異なる非線形の補間曲線を作る。
+// 軸ごとに base をずらし、列の入れ替わりがあれば値が変わるようにする。
+func newTestCurve(base float64) *mmath.Curve {
+	curve := mmath.NewCurve()
+	curve.Start = mmath.Vec2{X: base, Y: base + 1}
+	curve.End = mmath.Vec2{X: base + 2, Y: base + 3}
+	return curve
+}
+
+// newTestRotation は度数法のオイラー角から回転を作る。
+func newTestRotation(x float64, y float64, z float64) *mmath.Quaternion {
+	return mmath.NewQuaternionFromEulerAngles(x*math.Pi/180, y*math.Pi/180, z*math.Pi/180)
+}
+
+// newTestCsvMotion は全区分に非線形の補間曲線や既定以外の値を持つキーを入れたモーションを作る。
+func newTestCsvMotion() *motion.VmdMotion {
+	motionData := motion.NewVmdMotion("")
+	motionData.SetName("テストモデル")
+
+	motionData.BoneFrames = motion.NewBoneFrames()
+	for i, name := range []string{"センター", "左腕"} {
+		frames := motion.NewBoneNameFrames(name)
+		for j, frame := range []motion.Frame{0, 15} {
+			value := motion.NewBoneFrame(frame)
+			value.Position = &mmath.Vec3{X: float64(i) + 0.5, Y: -1.25, Z: float64(j) * 2}
+			value.Rotation = newTestRotation(10+float64(j), -20, 30+float64(i))
+			value.Curves = motion.NewBoneCurves()
+			base := float64(i*40 + j*20)
+			value.Curves.TranslateX = newTestCurve(base + 1)
+			value.Curves.TranslateY = newTestCurve(base + 5)
+			value.Curves.TranslateZ = newTestCurve(base + 9)
+			value.Curves.Rotate = newTestCurve(base + 13)
+			frames.Append(value)
+		}
+		motionData.BoneFrames.Update(frames)
+	}
+
+	motionData.MorphFrames = motion.NewMorphFrames()
+	morphFrames := motion.NewMorphNameFrames("あ")
+	for i, ratio := range []float64{0.25, 1} {
+		value := motion.NewMorphFrame(motion.Frame(i * 10))
+		value.Ratio = ratio
+		morphFrames.Append(value)
+	}
+	motionData.MorphFrames.Update(morphFrames)
+
+	motionData.CameraFrames = motion.NewCameraFrames()
+	camera := motion.NewCameraFrame(5)
+	camera.Distance = -45.5
+	camera.Position = &mmath.Vec3{X: 1, Y: 12.5, Z: -3}
+	camera.Degrees = &mmath.Vec3{X: 15, Y: -30, Z: 2.5}
+	camera.ViewOfAngle = 27
+	camera.IsPerspectiveOff = true
+	camera.Curves = motion.NewCameraCurves()
+	camera.Curves.TranslateX = newTestCurve(1)
+	camera.Curves.TranslateY = newTestCurve(11)
+	camera.Curves.TranslateZ = newTestCurve(21)
+	camera.Curves.Rotate = newTestCurve(31)
+	camera.Curves.Distance = newTestCurve(41)
+	camera.Curves.ViewOfAngle = newTestCurve(51)
+	motionData.CameraFrames.Append(camera)
+
+	motionData.LightFrames = motion.NewLightFrames()
+	light := motion.NewLightFrame(3)
+	light.Color = &mmath.Vec3{X: 0.25, Y: 0.5, Z: 0.75}
+	light.Position = &mmath.Vec3{X: -0.5, Y: -1, Z: 0.5}
+	motionData.LightFrames.Append(light)
+
+	motionData.ShadowFrames = motion.NewShadowFrames()
+	shadow := motion.NewShadowFrame(7)
+	shadow.ShadowMode = 2
+	shadow.Distance = 0.0875
+	motionData.ShadowFrames.Append(shadow)
+
+	motionData.IkFrames = motion.NewIkFrames()
+	ik := motion.NewIkFrame(9)
+	ik.Visible = false
+	ik.IkList = []*motion.IkEnabledFrame{
+		motion.NewIkEnabledFrame("左足ＩＫ", false),
+		motion.NewIkEnabledFrame("右足ＩＫ", true),
+	}
+	motionData.IkFrames.Append(ik)
+	return motionData
+}
+
+func TestCsvRoundTrip(t *testing.T) {
+	source := newTestCsvMotion()
+	var buffer bytes.Buffer
+	if err := WriteCsv(&buffer, source); err != nil {
+		t.Fatalf("WriteCsv: %v", err)
+	}
+	text, err := decodeCsv(buffer.Bytes())
+	if err != nil {
+		t.Fatalf("decodeCsv: %v", err)
+	}
+	loaded, err := ReadCsv(strings.NewReader(text), "")
+	if err != nil {
+		t.Fatalf("ReadCsv: %v", err)
+	}
+	if loaded.Name() != source.Name() {
+		t.Fatalf("モデル名 = %q, want %q", loaded.Name(), source.Name())
+	}
+	diff := minteractor.DiffMotion(source, loaded)
+	if diff.HasChanges() {
+		for _, track := range diff.Tracks {
+			t.Errorf("%s %s: 追加%d 削除%d 変更%d", track.Section, track.Name, track.Added, track.Removed, track.Changed)
+		}
+		t.Fatalf("書き出して読み込んだモーションが元と一致しません")
+	}
+}
+
+// curveCells は行の補間パラメータの列を数値にする。
+func curveCells(t *testing.T, row []string, start int, count int) []int {
+	t.Helper()
+	if len(row) < start+count {
+		t.Fatalf("列数 = %d, want %d 以上", len(row), start+count)
+	}
+	values := make([]int, count)
+	for i := range values {
+		value, err := strconv.Atoi(row[start+i])
+		if err != nil {
+			t.Fatalf("%d列目: %v", start+i+1, err)
+		}
+		values[i] = value
+	}
+	return values
+}
+
+// assertCurveCells は補間パラメータの列が期待どおりの並びか確認する。
+func assertCurveCells(t *testing.T, got []int, want []int) {
+	t.Helper()
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("補間パラメータの並び = %v, want %v", got, want)
+		}
+	}
+}
+
+// 往復の検証では書き出しと読み込みが同じように入れ替わった場合を見逃すため、列の並び自体も確認する。
+func TestCsvCurveColumns(t *testing.T) {
+	source := newTestCsvMotion()
+
+	// ボーンはパラメータごとに4軸を並べる（X_x1, Y_x1, Z_x1, R_x1, X_y1, ...）。
+	bone := source.BoneFrames.Get("センター").Get(0)
+	assertCurveCells(t, curveCells(t, boneRow("センター", 0, bone), 8, 16), []int{
+		1, 5, 9, 13,
+		2, 6, 10, 14,
+		3, 7, 11, 15,
+		4, 8, 12, 16,
+	})
+
+	// カメラは軸ごとに x1, x2, y1, y2 を並べる。
+	camera := source.CameraFrames.Get(5)
+	assertCurveCells(t, curveCells(t, cameraRow(5, camera), 8, 24), []int{
+		1, 3, 2, 4,
+		11, 13, 12, 14,
+		21, 23, 22, 24,
+		31, 33, 32, 34,
+		41, 43, 42, 44,
+		51, 53, 52, 54,
+	})
+}
@@ -0,0 +1,56 @@
+// 指示: miu200521358
+package mgateway
+
+import (
+	"fmt"
+
+	"github.com/miu200521358/mlib_go/pkg/shared/hashable"
+
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// MotionFileReader は複数の形式のモーション読み込みリポジトリをまとめ、パスを読み込めるものに委ねる。
+type MotionFileReader struct {
+	readers []moutput.IFileReader
+}
+
+// NewMotionFileReader は読み込みリポジトリをまとめたリポジトリを生成する。先に指定したものを優先する。
+func NewMotionFileReader(readers ...moutput.IFileReader) *MotionFileReader {
+	return &MotionFileReader{readers: readers}
+}
+
+// CanLoad はいずれかのリポジトリが指定パスを読み込めるか判定する。
+func (r *MotionFileReader) CanLoad(path string) bool {
+	return r.readerFor(path) != nil
+}
+
+// Load は指定パスを読み込めるリポジトリでモーションを読み込む。
+func (r *MotionFileReader) Load(path string) (hashable.IHashable, error) {
+	reader := r.readerFor(path)
+	if reader == nil {
+		return nil, fmt.Errorf("読み込めないファイルです: %s", path)
+	}
+	return reader.Load(path)
+}
+
+// InferName は指定パスを読み込めるリポジトリで名前を推定する。
+func (r *MotionFileReader) InferName(path string) string {
+	reader := r.readerFor(path)
+	if reader == nil {
+		if len(r.readers) == 0 {
+			return ""
+		}
+		reader = r.readers[0]
+	}
+	return reader.InferName(path)
+}
+
+// readerFor は指定パスを読み込める最初のリポジトリを返す。
+func (r *MotionFileReader) readerFor(path string) moutput.IFileReader {
+	for _, reader := range r.readers {
+		if reader != nil && reader.CanLoad(path) {
+			return reader
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// 指示: miu200521358
+// Package mgateway はモーションを外部のファイル形式で読み書きする。
+package mgateway
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mlib_go/pkg/shared/hashable"
+	"golang.org/x/text/encoding/japanese"
+
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// vpdNewLine はVPDの改行コード。
+const vpdNewLine = "\r\n"
+
+// defaultVpdModelName は親ファイル名にモデル名がない場合の名前。
+const defaultVpdModelName = "model"
+
+// VpdWriter はモーションの0フレームのボーン/モーフをVPD(ポーズデータ)として保存する。
+type VpdWriter struct{}
+
+// NewVpdWriter はVPDの保存リポジトリを生成する。
+func NewVpdWriter() *VpdWriter {
+	return &VpdWriter{}
+}
+
+// Save はモーションをVPDファイルに保存する。
+func (w *VpdWriter) Save(path string, data hashable.IHashable, _ moutput.SaveOptions) error {
+	motionData, ok := data.(*motion.VmdMotion)
+	if !ok || motionData == nil {
+		return fmt.Errorf("VPDに保存できるのはモーションだけです")
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteVpd(file, motionData); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WriteVpd はモーションの0フレームのボーン/モーフをShift-JISのVPD形式で書き出す。
+// VPDには名前の長さの制限がないため、VMDで切り詰められる名前もそのまま書き出す。
+func WriteVpd(w io.Writer, motionData *motion.VmdMotion) error {
+	if motionData == nil {
+		return fmt.Errorf("モーションがありません")
+	}
+	var builder strings.Builder
+	modelName := motionData.Name()
+	if modelName == "" {
+		modelName = defaultVpdModelName
+	}
+	boneNames := make([]string, 0)
+	if motionData.BoneFrames != nil {
+		boneNames = motionData.BoneFrames.Names()
+	}
+	builder.WriteString("Vocaloid Pose Data file" + vpdNewLine)
+	builder.WriteString(vpdNewLine)
+	fmt.Fprintf(&builder, "%s.osm;\t\t// 親ファイル名%s", modelName, vpdNewLine)
+	fmt.Fprintf(&builder, "%d;\t\t\t\t// 総ポーズボーン数%s", len(boneNames), vpdNewLine)
+	builder.WriteString(vpdNewLine)
+
+	for i, name := range boneNames {
+		value := motionData.BoneFrames.Get(name).Get(0)
+		position := mmath.NewVec3()
+		rotation := mmath.NewQuaternion()
+		if value != nil && value.Position != nil {
+			position = value.Position
+		}
+		if value != nil && value.Rotation != nil {
+			rotation = value.Rotation
+		}
+		fmt.Fprintf(&builder, "Bone%d{%s%s", i, name, vpdNewLine)
+		fmt.Fprintf(&builder, "  %.6f,%.6f,%.6f;\t\t\t\t// trans x,y,z%s",
+			position.X, position.Y, position.Z, vpdNewLine)
+		fmt.Fprintf(&builder, "  %.6f,%.6f,%.6f,%.6f;\t\t// Quaternion x,y,z,w%s",
+			rotation.X(), rotation.Y(), rotation.Z(), rotation.W(), vpdNewLine)
+		builder.WriteString("}" + vpdNewLine)
+		builder.WriteString(vpdNewLine)
+	}
+
+	if motionData.MorphFrames != nil {
+		for i, name := range motionData.MorphFrames.Names() {
+			ratio := 0.0
+			if value := motionData.MorphFrames.Get(name).Get(0); value != nil {
+				ratio = value.Ratio
+			}
+			fmt.Fprintf(&builder, "Morph%d{%s%s", i, name, vpdNewLine)
+			fmt.Fprintf(&builder, "  %.6f;\t\t\t\t// weight%s", ratio, vpdNewLine)
+			builder.WriteString("}" + vpdNewLine)
+			builder.WriteString(vpdNewLine)
+		}
+	}
+
+	encoded, err := japanese.ShiftJIS.NewEncoder().String(builder.String())
+	if err != nil {
+		return fmt.Errorf("Shift-JISに変換できない名前があります: %w", err)
+	}
+	_, err = io.WriteString(w, encoded)
+	return err
+}
@@ -7,27 +7,215 @@ const (
 	HelpUsageTitle = "使い方"
 	HelpUsage      = "使い方説明"
 
-	LabelFile                = "ファイル"
-	LabelSettingSave         = "設定保存"
-	LabelSafeMotionSave      = "IK・外部親なしモーション保存"
-	LabelModelFile           = "モデルファイル"
-	LabelModelFileTip        = "モデルファイルを選択してください"
-	LabelMotionFile          = "モーションファイル"
-	LabelMotionFileTip       = "モーションファイルを選択してください"
-	LabelOkBone              = "OKボーン"
-	LabelOkBoneTip           = "OKボーン説明"
-	LabelOkMorph             = "OKモーフ"
-	LabelOkMorphTip          = "OKモーフ説明"
-	LabelNgBone              = "NGボーン"
-	LabelNgBoneTip           = "NGボーン説明"
-	LabelNgMorph             = "NGモーフ"
-	LabelNgMorphTip          = "NGモーフ説明"
-	LogSaveSuccess           = "保存成功"
-	LogSaveSuccessDetail     = "保存成功メッセージ"
-	LogSaveFailure           = "保存失敗"
-	LogSaveFailureDetail     = "保存失敗メッセージ"
-	LogSafeSaveSuccess       = "IK・外部親なし保存成功"
-	LogSafeSaveSuccessDetail = "IK・外部親なし保存成功メッセージ"
-	LogSafeSaveFailure       = "IK・外部親なし保存失敗"
-	LogSafeSaveFailureDetail = "IK・外部親なし保存失敗メッセージ"
+	LabelFile                  = "ファイル"
+	LabelSettingSave           = "設定保存"
+	LabelSafeMotionSave        = "IK・外部親なしモーション保存"
+	LabelModelFile             = "モデルファイル"
+	LabelModelFileTip          = "モデルファイルを選択してください"
+	LabelMotionFile            = "モーションファイル"
+	LabelMotionFileTip         = "モーションファイルを選択してください"
+	LabelOkBone                = "OKボーン"
+	LabelOkBoneTip             = "OKボーン説明"
+	LabelOkMorph               = "OKモーフ"
+	LabelOkMorphTip            = "OKモーフ説明"
+	LabelNgBone                = "NGボーン"
+	LabelNgBoneTip             = "NGボーン説明"
+	LabelNgMorph               = "NGモーフ"
+	LabelNgMorphTip            = "NGモーフ説明"
+	LabelMappedBone            = "対応付けボーン"
+	LabelMappedBoneTip         = "対応付けボーン説明"
+	LabelMappedMorph           = "対応付けモーフ"
+	LabelMappedMorphTip        = "対応付けモーフ説明"
+	LabelAliasLoad             = "別名辞書読込"
+	LabelAliasLoadTip          = "別名辞書読込説明"
+	LabelMatchEnglish          = "英名照合"
+	LabelMatchEnglishTip       = "英名照合説明"
+	LabelTrackStatsTip         = "トラック統計説明"
+	LabelTrackConstant         = "固定"
+	LabelProfileLoad           = "ボーンプロファイル読込"
+	LabelProfileLoadTip        = "ボーンプロファイル読込説明"
+	LabelWeightsLoad           = "スコア重み付け読込"
+	LabelWeightsLoadTip        = "スコア重み付け読込説明"
+	LabelAudit                 = "不足プロファイルボーン"
+	LabelAuditTip              = "不足プロファイルボーン説明"
+	LabelLipSync               = "不足リップシンクモーフ"
+	LabelLipSyncTip            = "不足リップシンクモーフ説明"
+	LabelIkFrame               = "IK切替ボーン"
+	LabelIkFrameTip            = "IK切替ボーン説明"
+	LabelAmbiguousTag          = "曖昧"
+	LabelStrip                 = "除去対象"
+	LabelStripTip              = "除去対象説明"
+	LabelStripBakeIk           = "IK焼き込み"
+	LabelStripBakeIkTip        = "IK焼き込み説明"
+	LabelStripBakeEvery        = "IK全フレーム焼き込み"
+	LabelStripBakeEveryTip     = "IK全フレーム焼き込み説明"
+	LabelStripIk               = "IKフレーム"
+	LabelStripIkTip            = "IKフレーム除去説明"
+	LabelStripCamera           = "カメラ"
+	LabelStripCameraTip        = "カメラ除去説明"
+	LabelStripLight            = "照明"
+	LabelStripLightTip         = "照明除去説明"
+	LabelStripShadow           = "セルフ影"
+	LabelStripShadowTip        = "セルフ影除去説明"
+	LabelStripMorphs           = "全モーフ"
+	LabelStripMorphsTip        = "全モーフ除去説明"
+	LabelStripBones            = "全ボーン"
+	LabelStripBonesTip         = "全ボーン除去説明"
+	LabelStripNames            = "名前指定"
+	LabelStripNamesTip         = "名前指定除去説明"
+	LabelStripPattern          = "正規表現"
+	LabelStripPatternTip       = "正規表現除去説明"
+	LabelStripNg               = "NGのみ"
+	LabelStripNgTip            = "NGのみ除去説明"
+	LabelSafeMotionPreview     = "保存前差分確認"
+	LabelSafeMotionPreviewTip  = "保存前差分確認説明"
+	LabelDiffBone              = "差分ボーン"
+	LabelDiffMorph             = "差分モーフ"
+	LabelFitMotionSave         = "モデル適合モーション保存"
+	LabelFitMotionSaveTip      = "モデル適合モーション保存説明"
+	LabelRenameMotionSave      = "名前変換モーション保存"
+	LabelRenameMotionSaveTip   = "名前変換モーション保存説明"
+	LabelRenamePolicy          = "重複時"
+	LabelRenamePolicyTip       = "重複時説明"
+	LabelRenameOverwrite       = "上書き"
+	LabelRenameSkip            = "スキップ"
+	LabelRenameMerge           = "統合"
+	LabelRenameSuggest         = "候補も変換"
+	LabelRenameSuggestTip      = "候補も変換説明"
+	LabelRangeMotionSave       = "区間書き出し"
+	LabelRangeMotionSaveTip    = "区間書き出し説明"
+	LabelRangeStart            = "開始"
+	LabelRangeEnd              = "終了"
+	LabelRangeFromPlayer       = "現在位置"
+	LabelRangeFromPlayerTip    = "現在位置説明"
+	LabelRetimeMotionSave      = "時間変換保存"
+	LabelRetimeMotionSaveTip   = "時間変換保存説明"
+	LabelRetimeMode            = "変換方法"
+	LabelRetimeStretch         = "伸縮"
+	LabelRetimeResample        = "打ち直し"
+	LabelRetimeFactor          = "倍率"
+	LabelRetimeFactorTip       = "倍率説明"
+	LabelRetimeInterval        = "間隔"
+	LabelRetimeIntervalTip     = "間隔説明"
+	LabelRetimeLossy           = "再現できない変換を許可"
+	LabelRetimeLossyTip        = "再現できない変換を許可説明"
+	LabelMirrorMotionSave      = "左右反転保存"
+	LabelMirrorMotionSaveTip   = "左右反転保存説明"
+	LabelPoseExport            = "ポーズ書き出し"
+	LabelPoseExportTip         = "ポーズ書き出し説明"
+	LabelCsvExport             = "CSV出力"
+	LabelCsvExportTip          = "CSV出力説明"
+	LabelCsvLoad               = "CSV読込"
+	LabelCsvLoadTip            = "CSV読込説明"
+	LabelReduceMotionSave      = "キー間引き保存"
+	LabelReduceMotionSaveTip   = "キー間引き保存説明"
+	LabelReduceAngle           = "回転誤差"
+	LabelReducePosition        = "位置誤差"
+	LabelReduceMorph           = "モーフ誤差"
+	LabelReduceToleranceTip    = "間引き許容誤差説明"
+	LabelReduceFitCurves       = "補間曲線を当てはめる"
+	LabelReduceFitCurvesTip    = "補間曲線を当てはめる説明"
+	LabelPoseImport            = "VPD変換"
+	LabelPoseImportTip         = "VPD変換説明"
+	LabelPoseFolder            = "VPDフォルダ変換"
+	LabelPoseSpacing           = "キー間隔"
+	LabelPoseSpacingTip        = "キー間隔説明"
+	LabelPosePreset            = "補間"
+	LabelPosePresetTip         = "補間説明"
+	LabelPresetLinear          = "補間線形"
+	LabelPresetEaseInOut       = "補間加減速"
+	LabelPresetEaseIn          = "補間加速"
+	LabelPresetEaseOut         = "補間減速"
+	LabelOutputTemplate        = "出力ファイル名"
+	LabelOutputTemplateTip     = "出力ファイル名説明"
+	LabelOutputDir             = "出力先"
+	LabelOutputDirTip          = "出力先説明"
+	LabelOutputDirSelect       = "出力先選択"
+	LabelOutputCollision       = "同名ファイル"
+	LabelOutputCollisionTip    = "同名ファイル説明"
+	LabelCollisionOverwrite    = "上書き"
+	LabelCollisionIncrement    = "連番"
+	LabelCollisionSkip         = "スキップ"
+	LabelCollisionAsk          = "確認"
+	LabelOverwriteConfirm      = "上書き確認"
+	LabelOverwriteConfirmTip   = "上書き確認メッセージ"
+	LabelExportReport          = "レポート出力"
+	LabelExportReportTip       = "レポート出力説明"
+	LabelScore                 = "互換スコア"
+	LabelScoreTip              = "互換スコア説明"
+	LogSaveSuccess             = "保存成功"
+	LogSaveSuccessDetail       = "保存成功メッセージ"
+	LogSaveFailure             = "保存失敗"
+	LogSaveFailureDetail       = "保存失敗メッセージ"
+	LogSafeSaveSuccess         = "IK・外部親なし保存成功"
+	LogSafeSaveSuccessDetail   = "IK・外部親なし保存成功メッセージ"
+	LogSafeSaveFailure         = "IK・外部親なし保存失敗"
+	LogSafeSaveFailureDetail   = "IK・外部親なし保存失敗メッセージ"
+	LogSafeIkBake              = "IK焼き込み結果"
+	LogSafeNoOperation         = "除去操作未選択"
+	LogSafeDiff                = "保存前差分"
+	LogSafeDiffPath            = "保存前差分保存先"
+	LogSafeDiffSection         = "保存前差分区分"
+	LogSafeDiffTrack           = "保存前差分トラック"
+	LogSafeDiffNone            = "保存前差分なし"
+	LogSafeDiffFailure         = "保存前差分失敗"
+	LogFitSaveSuccess          = "モデル適合保存成功"
+	LogFitSaveSuccessDetail    = "モデル適合保存成功メッセージ"
+	LogFitSaveDroppedBones     = "モデル適合保存除外ボーン"
+	LogFitSaveDroppedMorphs    = "モデル適合保存除外モーフ"
+	LogFitSaveFailure          = "モデル適合保存失敗"
+	LogFitSaveFailureDetail    = "モデル適合保存失敗メッセージ"
+	LogRenameSaveSuccess       = "名前変換保存成功"
+	LogRenameSaveSuccessDetail = "名前変換保存成功メッセージ"
+	LogRenameSaveRenamed       = "名前変換保存変換"
+	LogRenameSaveSkipped       = "名前変換保存スキップ"
+	LogRenameSaveTooLong       = "名前変換保存名前欄超過"
+	LogRenameSaveFailure       = "名前変換保存失敗"
+	LogRenameSaveFailureDetail = "名前変換保存失敗メッセージ"
+	LogOutputSkipped           = "保存スキップ"
+	LogOutputSkippedDetail     = "保存スキップメッセージ"
+	LogRangeSaveSuccess        = "区間書き出し成功"
+	LogRangeSaveSuccessDetail  = "区間書き出し成功メッセージ"
+	LogRangeSaveFailure        = "区間書き出し失敗"
+	LogRangeSaveFailureDetail  = "区間書き出し失敗メッセージ"
+	LogRetimeSaveSuccess       = "時間変換保存成功"
+	LogRetimeSaveSuccessDetail = "時間変換保存成功メッセージ"
+	LogRetimeSaveFailure       = "時間変換保存失敗"
+	LogRetimeSaveFailureDetail = "時間変換保存失敗メッセージ"
+	LogRetimeCollision         = "時間変換重なり"
+	LogRetimeDropped           = "時間変換欠落"
+	LogMirrorSaveSuccess       = "左右反転保存成功"
+	LogMirrorSaveSuccessDetail = "左右反転保存成功メッセージ"
+	LogMirrorSaveFailure       = "左右反転保存失敗"
+	LogMirrorSaveFailureDetail = "左右反転保存失敗メッセージ"
+	LogMirrorSaveSwapped       = "左右反転入替"
+	LogMirrorSaveUnpaired      = "左右反転対応なし"
+	LogPoseExportSuccess       = "ポーズ書き出し成功"
+	LogPoseExportSuccessDetail = "ポーズ書き出し成功メッセージ"
+	LogPoseExportFailure       = "ポーズ書き出し失敗"
+	LogPoseExportFailureDetail = "ポーズ書き出し失敗メッセージ"
+	LogCsvExportSuccess        = "CSV出力成功"
+	LogCsvExportSuccessDetail  = "CSV出力成功メッセージ"
+	LogCsvExportFailure        = "CSV出力失敗"
+	LogCsvExportFailureDetail  = "CSV出力失敗メッセージ"
+	LogReduceSaveSuccess       = "キー間引き保存成功"
+	LogReduceSaveSuccessDetail = "キー間引き保存成功メッセージ"
+	LogReduceSaveFailure       = "キー間引き保存失敗"
+	LogReduceSaveFailureDetail = "キー間引き保存失敗メッセージ"
+	LogReduceBone              = "キー間引きボーン"
+	LogReduceMorph             = "キー間引きモーフ"
+	LogPoseImportSuccess       = "VPD変換成功"
+	LogPoseImportSuccessDetail = "VPD変換成功メッセージ"
+	LogPoseImportFailure       = "VPD変換失敗"
+	LogPoseImportFailureDetail = "VPD変換失敗メッセージ"
+	LogAliasLoadSuccess        = "別名辞書読込成功"
+	LogAliasLoadFailure        = "別名辞書読込失敗"
+	LogProfileLoadSuccess      = "ボーンプロファイル読込成功"
+	LogProfileLoadFailure      = "ボーンプロファイル読込失敗"
+	LogWeightsLoadSuccess      = "スコア重み付け読込成功"
+	LogWeightsLoadFailure      = "スコア重み付け読込失敗"
+	LogReportSuccess           = "レポート出力成功"
+	LogReportSuccessDetail     = "レポート出力成功メッセージ"
+	LogReportFailure           = "レポート出力失敗"
+	LogReportFailureDetail     = "レポート出力失敗メッセージ"
 )
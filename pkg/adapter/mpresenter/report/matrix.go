@@ -0,0 +1,62 @@
+// 指示: miu200521358
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/minteractor"
+)
+
+// WriteMatrixCSV は一括判定結果を「行=モデル、列=モーション」のCSVで書き出す。
+// 各セルは「スコア / OK率% (OK件数/NG件数)」、失敗時は「ERROR: 内容」となる。
+func WriteMatrixCSV(w io.Writer, matrix *minteractor.CompatibilityMatrix) error {
+	if matrix == nil {
+		return fmt.Errorf("判定結果がありません")
+	}
+	if _, err := io.WriteString(w, utf8Bom); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	header := make([]string, 0, len(matrix.MotionPaths)+1)
+	header = append(header, "model \\ motion")
+	header = append(header, matrix.MotionPaths...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i, modelPath := range matrix.ModelPaths {
+		record := make([]string, 0, len(matrix.MotionPaths)+1)
+		record = append(record, modelPath)
+		for _, cell := range matrix.Cells[i] {
+			record = append(record, formatMatrixCell(cell))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteMatrixFile は一括判定結果をCSVファイルに保存する。
+func WriteMatrixFile(path string, matrix *minteractor.CompatibilityMatrix) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteMatrixCSV(file, matrix); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// formatMatrixCell は1組の判定件数をセル文字列にする。
+func formatMatrixCell(cell minteractor.CompatibilityCell) string {
+	if cell.Err != nil {
+		return "ERROR: " + cell.Err.Error()
+	}
+	return fmt.Sprintf("score %.1f / %.1f%% (OK %d / NG %d)", cell.Score, cell.OkPercent(), cell.OkCount(), cell.NgCount())
+}
@@ -0,0 +1,369 @@
+// 指示: miu200521358
+// Package report はOK/NG判定結果を帳票として出力する。
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/minteractor"
+)
+
+// Format は帳票の出力形式を表す。
+type Format string
+
+// 帳票の出力形式一覧。
+const (
+	FormatJSON     Format = "json"
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "md"
+	FormatHTML     Format = "html"
+)
+
+// 帳票行の分類。
+const (
+	CategoryOk      = "OK"
+	CategoryNg      = "NG"
+	CategoryMapped  = "MAPPED"
+	CategoryEnglish = "ENGLISH"
+	CategoryProfile = "PROFILE"
+	CategoryLipSync = "LIPSYNC"
+	CategoryIk      = "IK"
+	// CategoryTruncated はVMDの15バイト制限で切り詰められた名前の一致。
+	CategoryTruncated = "TRUNCATED"
+	// CategoryAmbiguous は切り詰められた名前が複数に前方一致したもの。
+	CategoryAmbiguous = "AMBIGUOUS"
+)
+
+// 帳票行の種別。
+const (
+	KindBone  = "bone"
+	KindMorph = "morph"
+)
+
+// Source は帳票作成の入力を表す。
+type Source struct {
+	ModelPath   string
+	MotionPath  string
+	ToolName    string
+	ToolVersion string
+	Result      minteractor.CheckResult
+	// Score は互換スコア。nilの場合は Weights の重み付けで算出する。
+	Score *minteractor.CompatibilityScore
+	// Weights は互換スコアの重み付け。nilの場合は既定の重み付けを使う。
+	Weights *minteractor.ScoreWeights
+	// Translate は説明文の翻訳関数。nilの場合は日本語のまま出力する。
+	Translate func(key string) string
+}
+
+// Report は出力する帳票を表す。
+type Report struct {
+	ToolName    string    `json:"toolName"`
+	ToolVersion string    `json:"toolVersion"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ModelPath   string    `json:"modelPath"`
+	ModelHash   string    `json:"modelHash"`
+	MotionPath  string    `json:"motionPath"`
+	MotionHash  string    `json:"motionHash"`
+	Summary     Summary   `json:"summary"`
+	Score       Score     `json:"score"`
+	Rows        []Row     `json:"rows"`
+}
+
+// Score は互換スコアと主な減点要因を表す。
+type Score struct {
+	Value        float64            `json:"value"`
+	Contributors []ScoreContributor `json:"contributors"`
+}
+
+// ScoreContributor は減点要因の1件を表す。
+type ScoreContributor struct {
+	Kind    string  `json:"kind"`
+	Name    string  `json:"name"`
+	Tier    string  `json:"tier"`
+	Penalty float64 `json:"penalty"`
+}
+
+// Summary は分類ごとの件数を表す。
+type Summary struct {
+	OkBones  int `json:"okBones"`
+	OkMorphs int `json:"okMorphs"`
+	NgBones  int `json:"ngBones"`
+	NgMorphs int `json:"ngMorphs"`
+	Mapped   int `json:"mapped"`
+	Missing  int `json:"missingProfileBones"`
+	// Ambiguous は切り詰められた名前が複数に前方一致した件数。
+	Ambiguous int `json:"ambiguous"`
+	// MissingLipSync はモデルにない母音/まばたきモーフの件数。
+	MissingLipSync int `json:"missingLipSyncMorphs"`
+	// InvalidIk はIK切替がモデルに反映されないボーンの件数。
+	InvalidIk int `json:"invalidIkBones"`
+}
+
+// Row は帳票の1行を表す。
+type Row struct {
+	Category   string  `json:"category"`
+	Kind       string  `json:"kind"`
+	Name       string  `json:"name"`
+	Target     string  `json:"target,omitempty"`
+	KeyCount   int     `json:"keyCount"`
+	FirstFrame float32 `json:"firstFrame"`
+	LastFrame  float32 `json:"lastFrame"`
+	Constant   bool    `json:"constant"`
+	Note       string  `json:"note,omitempty"`
+}
+
+// Build は判定結果とファイル情報から帳票を作成する。
+func Build(source Source) (*Report, error) {
+	modelHash, err := hashFile(source.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	motionHash, err := hashFile(source.MotionPath)
+	if err != nil {
+		return nil, err
+	}
+	translate := source.Translate
+	if translate == nil {
+		translate = func(key string) string { return key }
+	}
+
+	result := source.Result
+	rows := make([]Row, 0)
+	rows = appendTrackRows(rows, CategoryOk, KindBone, result.OkBones, nil)
+	rows = appendMorphGroupRows(rows, CategoryOk, result.OkMorphGroups, nil, translate)
+	rows = appendTrackRows(rows, CategoryNg, KindBone, result.NgBones, result.NgBoneSuggestions)
+	rows = appendMorphGroupRows(rows, CategoryNg, result.NgMorphGroups, result.NgMorphSuggestions, translate)
+	rows = appendMappedRows(rows, CategoryMapped, KindBone, result.MappedBones)
+	rows = appendMappedRows(rows, CategoryMapped, KindMorph, result.MappedMorphs)
+	rows = appendMappedRows(rows, CategoryEnglish, KindBone, result.EnglishBones)
+	rows = appendMappedRows(rows, CategoryEnglish, KindMorph, result.EnglishMorphs)
+	rows = appendMappedRows(rows, CategoryTruncated, KindBone, result.TruncatedBones)
+	rows = appendMappedRows(rows, CategoryTruncated, KindMorph, result.TruncatedMorphs)
+	rows = appendAmbiguousRows(rows, KindBone, result.AmbiguousBones)
+	rows = appendAmbiguousRows(rows, KindMorph, result.AmbiguousMorphs)
+	for _, entry := range result.ProfileAudit {
+		row := newTrackRow(CategoryProfile, KindBone, entry.TrackEntry)
+		row.Target = entry.Profile
+		row.Note = translate(string(entry.Effect))
+		rows = append(rows, row)
+	}
+	for _, entry := range result.LipSyncAudit {
+		row := newTrackRow(CategoryLipSync, KindMorph, entry.TrackEntry)
+		row.Note = translate(string(entry.Effect))
+		rows = append(rows, row)
+	}
+
+	for _, entry := range result.IkBones {
+		rows = append(rows, newIkRow(entry, translate))
+	}
+
+	score := source.Score
+	if score == nil {
+		weights := minteractor.DefaultScoreWeights()
+		if source.Weights != nil {
+			weights = *source.Weights
+		}
+		computed := minteractor.ScoreCompatibility(result, weights)
+		score = &computed
+	}
+
+	return &Report{
+		ToolName:    source.ToolName,
+		ToolVersion: source.ToolVersion,
+		CreatedAt:   time.Now(),
+		ModelPath:   source.ModelPath,
+		ModelHash:   modelHash,
+		MotionPath:  source.MotionPath,
+		MotionHash:  motionHash,
+		Summary: Summary{
+			OkBones:  len(result.OkBones),
+			OkMorphs: len(result.OkMorphs),
+			NgBones:  len(result.NgBones),
+			NgMorphs: len(result.NgMorphs),
+			Mapped: len(result.MappedBones) + len(result.MappedMorphs) +
+				len(result.EnglishBones) + len(result.EnglishMorphs) +
+				len(result.TruncatedBones) + len(result.TruncatedMorphs),
+			Ambiguous:      len(result.AmbiguousBones) + len(result.AmbiguousMorphs),
+			Missing:        len(result.ProfileAudit),
+			MissingLipSync: len(result.LipSyncAudit),
+			InvalidIk:      countInvalidIk(result.IkBones),
+		},
+		Score: buildScore(*score),
+		Rows:  rows,
+	}, nil
+}
+
+// FormatFromPath は拡張子から出力形式を判定する。
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".csv":
+		return FormatCSV, nil
+	case ".md", ".markdown":
+		return FormatMarkdown, nil
+	case ".html", ".htm":
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("未対応の帳票形式です: %s", path)
+	}
+}
+
+// Write は指定形式で帳票を書き出す。
+func Write(w io.Writer, format Format, report *Report) error {
+	if report == nil {
+		return fmt.Errorf("帳票がありません")
+	}
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, report)
+	case FormatCSV:
+		return writeCSV(w, report)
+	case FormatMarkdown:
+		return writeMarkdown(w, report)
+	case FormatHTML:
+		return writeHTML(w, report)
+	default:
+		return fmt.Errorf("未対応の帳票形式です: %s", format)
+	}
+}
+
+// WriteFile は拡張子に応じた形式で帳票をファイルに保存する。
+func WriteFile(path string, report *Report) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, format, report); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// appendTrackRows はトラック一覧を帳票行として追加する。
+func appendTrackRows(rows []Row, category string, kind string, entries []minteractor.TrackEntry, suggestions map[string][]minteractor.NameSuggestion) []Row {
+	for _, entry := range entries {
+		row := newTrackRow(category, kind, entry)
+		if suggestion, ok := minteractor.TopSuggestion(suggestions, entry.Name); ok {
+			row.Target = suggestion.Name
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// appendMorphGroupRows は操作パネル区分ごとのモーフ一覧を、区分名を備考にして追加する。
+func appendMorphGroupRows(rows []Row, category string, groups []minteractor.MorphPanelGroup, suggestions map[string][]minteractor.NameSuggestion, translate func(key string) string) []Row {
+	for _, group := range groups {
+		start := len(rows)
+		rows = appendTrackRows(rows, category, KindMorph, group.Entries, suggestions)
+		for i := start; i < len(rows); i++ {
+			rows[i].Note = translate(string(group.Group))
+		}
+	}
+	return rows
+}
+
+// appendMappedRows は対応付け結果を帳票行として追加する。
+func appendMappedRows(rows []Row, category string, kind string, entries []minteractor.MappedName) []Row {
+	for _, entry := range entries {
+		row := newTrackRow(category, kind, entry.TrackEntry)
+		row.Target = entry.Target
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// appendAmbiguousRows は曖昧な切り詰め一致を、候補を「|」区切りで対応先にして追加する。
+func appendAmbiguousRows(rows []Row, kind string, entries []minteractor.AmbiguousName) []Row {
+	for _, entry := range entries {
+		row := newTrackRow(CategoryAmbiguous, kind, entry.TrackEntry)
+		row.Target = strings.Join(entry.Candidates, " | ")
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// newTrackRow はトラック統計を帳票行に変換する。
+func newTrackRow(category string, kind string, entry minteractor.TrackEntry) Row {
+	return Row{
+		Category:   category,
+		Kind:       kind,
+		Name:       entry.Name,
+		KeyCount:   entry.KeyCount,
+		FirstFrame: float32(entry.FirstFrame),
+		LastFrame:  float32(entry.LastFrame),
+		Constant:   entry.Constant,
+	}
+}
+
+// newIkRow はIK切替の判定結果を帳票行に変換する。開始/終了は最初と最後の切替フレーム。
+func newIkRow(entry minteractor.IkFrameEntry, translate func(key string) string) Row {
+	row := Row{
+		Category: CategoryIk,
+		Kind:     KindBone,
+		Name:     entry.Name,
+		KeyCount: entry.KeyCount,
+		Note:     translate(string(entry.Status)),
+	}
+	if len(entry.Toggles) > 0 {
+		row.FirstFrame = float32(entry.Toggles[0].Frame)
+		row.LastFrame = float32(entry.Toggles[len(entry.Toggles)-1].Frame)
+		row.Note += " (" + minteractor.FormatIkToggles(entry.Toggles) + ")"
+	}
+	return row
+}
+
+// countInvalidIk はIK切替が反映されないボーンの件数を返す。
+func countInvalidIk(entries []minteractor.IkFrameEntry) int {
+	count := 0
+	for _, entry := range entries {
+		if !entry.IsOk() {
+			count++
+		}
+	}
+	return count
+}
+
+// buildScore は互換スコアを帳票用に変換する。
+func buildScore(score minteractor.CompatibilityScore) Score {
+	contributors := make([]ScoreContributor, 0, len(score.Contributors))
+	for _, contributor := range score.Contributors {
+		contributors = append(contributors, ScoreContributor{
+			Kind:    contributor.Kind,
+			Name:    contributor.Name,
+			Tier:    string(contributor.Tier),
+			Penalty: contributor.Penalty,
+		})
+	}
+	return Score{Value: score.Score, Contributors: contributors}
+}
+
+// hashFile はファイル内容のSHA-256を16進数で返す。
+func hashFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
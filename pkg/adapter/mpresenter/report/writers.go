@@ -0,0 +1,178 @@
+// 指示: miu200521358
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// utf8Bom は表計算ソフトでUTF-8と認識させるためのBOM。
+const utf8Bom = "\ufeff"
+
+// csvHeader はCSV帳票の見出し行。
+var csvHeader = []string{"category", "kind", "name", "target", "keyCount", "firstFrame", "lastFrame", "constant", "note"}
+
+// writeJSON はJSON形式で書き出す。
+func writeJSON(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(report)
+}
+
+// writeCSV はCSV形式で書き出す。ファイル情報は先頭のコメント行に出力する。
+func writeCSV(w io.Writer, report *Report) error {
+	if _, err := io.WriteString(w, utf8Bom); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	for _, meta := range metaLines(report) {
+		if err := writer.Write([]string{"#", meta[0], meta[1]}); err != nil {
+			return err
+		}
+	}
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		if err := writer.Write(rowValues(row)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeMarkdown はMarkdown形式で書き出す。
+func writeMarkdown(w io.Writer, report *Report) error {
+	var builder strings.Builder
+	builder.WriteString("# " + escapeMarkdown(reportTitle(report)) + "\n\n")
+	for _, meta := range metaLines(report) {
+		fmt.Fprintf(&builder, "- **%s**: %s\n", meta[0], escapeMarkdown(meta[1]))
+	}
+	builder.WriteString("\n")
+	builder.WriteString("| " + strings.Join(csvHeader, " | ") + " |\n")
+	builder.WriteString("|" + strings.Repeat(" --- |", len(csvHeader)) + "\n")
+	for _, row := range report.Rows {
+		values := rowValues(row)
+		for i, value := range values {
+			values[i] = escapeMarkdown(value)
+		}
+		builder.WriteString("| " + strings.Join(values, " | ") + " |\n")
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// htmlTemplate は単体で閲覧できるHTML帳票のテンプレート。
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 1.5em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #999; padding: 2px 8px; }
+th { background: #eee; }
+tr.NG td { background: #fdd; }
+tr.AMBIGUOUS td { background: #fdd; }
+tr.PROFILE td { background: #ffe; }
+tr.LIPSYNC td { background: #ffe; }
+td.num { text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<ul>
+{{range .Meta}}<li><b>{{index . 0}}</b>: {{index . 1}}</li>
+{{end}}</ul>
+<table>
+<tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr class="{{.Category}}"><td>{{.Category}}</td><td>{{.Kind}}</td><td>{{.Name}}</td><td>{{.Target}}</td><td class="num">{{.KeyCount}}</td><td class="num">{{.FirstFrame}}</td><td class="num">{{.LastFrame}}</td><td>{{.Constant}}</td><td>{{.Note}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// writeHTML は単体のHTML形式で書き出す。
+func writeHTML(w io.Writer, report *Report) error {
+	return htmlTemplate.Execute(w, struct {
+		Title  string
+		Meta   [][2]string
+		Header []string
+		Rows   []Row
+	}{
+		Title:  reportTitle(report),
+		Meta:   metaLines(report),
+		Header: csvHeader,
+		Rows:   report.Rows,
+	})
+}
+
+// reportTitle は帳票の表題を返す。
+func reportTitle(report *Report) string {
+	if report.ToolName == "" {
+		return "Compatibility Report"
+	}
+	return report.ToolName + " Compatibility Report"
+}
+
+// metaLines は帳票のファイル情報と件数を見出しと値の組で返す。
+func metaLines(report *Report) [][2]string {
+	return [][2]string{
+		{"tool", strings.TrimSpace(report.ToolName + " " + report.ToolVersion)},
+		{"createdAt", report.CreatedAt.Format(time.RFC3339)},
+		{"model", report.ModelPath},
+		{"modelSha256", report.ModelHash},
+		{"motion", report.MotionPath},
+		{"motionSha256", report.MotionHash},
+		{"okBones", strconv.Itoa(report.Summary.OkBones)},
+		{"okMorphs", strconv.Itoa(report.Summary.OkMorphs)},
+		{"ngBones", strconv.Itoa(report.Summary.NgBones)},
+		{"ngMorphs", strconv.Itoa(report.Summary.NgMorphs)},
+		{"mapped", strconv.Itoa(report.Summary.Mapped)},
+		{"ambiguous", strconv.Itoa(report.Summary.Ambiguous)},
+		{"missingProfileBones", strconv.Itoa(report.Summary.Missing)},
+		{"missingLipSyncMorphs", strconv.Itoa(report.Summary.MissingLipSync)},
+		{"invalidIkBones", strconv.Itoa(report.Summary.InvalidIk)},
+		{"score", strconv.FormatFloat(report.Score.Value, 'f', 1, 64)},
+		{"scorePenalties", formatContributors(report.Score.Contributors)},
+	}
+}
+
+// formatContributors は減点要因を「名前 -点」の列挙にする。
+func formatContributors(contributors []ScoreContributor) string {
+	parts := make([]string, 0, len(contributors))
+	for _, contributor := range contributors {
+		parts = append(parts, fmt.Sprintf("%s -%.1f", contributor.Name, contributor.Penalty))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// rowValues は帳票行を見出し順の文字列にする。
+func rowValues(row Row) []string {
+	return []string{
+		row.Category,
+		row.Kind,
+		row.Name,
+		row.Target,
+		strconv.Itoa(row.KeyCount),
+		strconv.FormatFloat(float64(row.FirstFrame), 'f', -1, 32),
+		strconv.FormatFloat(float64(row.LastFrame), 'f', -1, 32),
+		strconv.FormatBool(row.Constant),
+		row.Note,
+	}
+}
+
+// escapeMarkdown は表の区切りを壊す文字をエスケープする。
+func escapeMarkdown(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	return strings.ReplaceAll(value, "\n", " ")
+}
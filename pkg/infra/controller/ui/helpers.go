@@ -5,7 +5,10 @@
 package ui
 
 import (
+	"path/filepath"
+
 	"github.com/miu200521358/mlib_go/pkg/shared/base/logging"
+	"github.com/miu200521358/walk/pkg/walk"
 )
 
 // logInfoLine は情報ログを1行として出力する。
@@ -45,3 +48,40 @@ func logErrorWithTitle(logger logging.ILogger, title string, err error) {
 	}
 	logger.Error("%s: %s", title, err.Error())
 }
+
+// selectOpenFilePaths は複数選択可能なファイル選択ダイアログを表示する。
+// キャンセル時は空の一覧を返す。
+func selectOpenFilePaths(title string, filter string) ([]string, error) {
+	dlg := new(walk.FileDialog)
+	dlg.Title = title
+	dlg.Filter = filter
+	accepted, err := dlg.ShowOpenMultiple(nil)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		return nil, nil
+	}
+	return dlg.FilePaths, nil
+}
+
+// selectSaveFilePath は保存先のファイル選択ダイアログを表示する。
+// 拡張子が省略された場合は選択中のフィルタの拡張子を補う。
+func selectSaveFilePath(title string, filter string, initialPath string, extensions []string) (string, error) {
+	dlg := new(walk.FileDialog)
+	dlg.Title = title
+	dlg.Filter = filter
+	dlg.FilePath = initialPath
+	accepted, err := dlg.ShowSave(nil)
+	if err != nil {
+		return "", err
+	}
+	if !accepted || dlg.FilePath == "" {
+		return "", nil
+	}
+	path := dlg.FilePath
+	if filepath.Ext(path) == "" && dlg.FilterIndex >= 1 && dlg.FilterIndex <= len(extensions) {
+		path += extensions[dlg.FilterIndex-1]
+	}
+	return path, nil
+}
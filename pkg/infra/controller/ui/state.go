@@ -5,6 +5,10 @@
 package ui
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
 	"github.com/miu200521358/mlib_go/pkg/adapter/io_common"
 	"github.com/miu200521358/mlib_go/pkg/domain/model"
 	"github.com/miu200521358/mlib_go/pkg/domain/motion"
@@ -13,14 +17,29 @@ import (
 	"github.com/miu200521358/mlib_go/pkg/shared/base/config"
 	"github.com/miu200521358/mlib_go/pkg/shared/base/i18n"
 	"github.com/miu200521358/mlib_go/pkg/shared/base/logging"
+	"github.com/miu200521358/walk/pkg/walk"
 
 	"github.com/miu200521358/mu_motion_viewer/pkg/adapter/mpresenter/messages"
+	"github.com/miu200521358/mu_motion_viewer/pkg/adapter/mpresenter/report"
 	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/minteractor"
 )
 
 const (
 	motionViewerWindowIndex = 0
 	motionViewerModelIndex  = 0
+
+	userConfigKeyAliasPaths   = "AliasDictionaryPaths"
+	userConfigKeyProfilePaths = "BoneProfilePaths"
+	userConfigKeyWeightsPath  = "ScoreWeightsPath"
+	userConfigKeyStripOps     = "SafeMotionStripOperations"
+	userConfigKeyOutput       = "MotionOutputSettings"
+	outputSettingTemplate     = "template"
+	outputSettingDir          = "dir"
+	outputSettingCollision    = "collision"
+	configPathHistoryLimit    = 10
+
+	mappedTagEnglish   = "EN"
+	mappedTagTruncated = "15B"
 )
 
 // motionViewerState はmu_motion_viewerの画面状態を保持する。
@@ -28,6 +47,8 @@ type motionViewerState struct {
 	translator i18n.II18n
 	logger     logging.ILogger
 	userConfig config.IUserConfig
+	appName    string
+	appVersion string
 
 	usecase *minteractor.MotionViewerUsecase
 
@@ -36,15 +57,67 @@ type motionViewerState struct {
 	motionPicker         *widget.FilePicker
 	saveModelButton      *widget.MPushButton
 	saveSafeMotionButton *widget.MPushButton
+	previewSafeButton    *widget.MPushButton
+	saveFitMotionButton  *widget.MPushButton
+	saveRenamedButton    *widget.MPushButton
+	saveMirrorButton     *widget.MPushButton
+	exportPoseButton     *widget.MPushButton
+	exportCsvButton      *widget.MPushButton
+	loadCsvButton        *widget.MPushButton
+	renamePolicyCombo    *walk.ComboBox
+	renameSuggestCheck   *walk.CheckBox
+	exportReportButton   *widget.MPushButton
 	okBoneList           *ListBoxWidget
 	okMorphList          *ListBoxWidget
 	ngBoneList           *ListBoxWidget
 	ngMorphList          *ListBoxWidget
+	mappedBoneList       *ListBoxWidget
+	mappedMorphList      *ListBoxWidget
+	loadAliasButton      *widget.MPushButton
+	matchEnglishCheck    *walk.CheckBox
+	loadProfileButton    *widget.MPushButton
+	loadWeightsButton    *widget.MPushButton
+	auditList            *ListBoxWidget
+	lipSyncList          *ListBoxWidget
+	ikList               *ListBoxWidget
+	scoreLabel           *walk.TextLabel
+	stripChecks          []*walk.CheckBox
+	bakeEveryCheck       *walk.CheckBox
+	stripNamesEdit       *walk.LineEdit
+	stripPatternEdit     *walk.LineEdit
+	outputTemplateEdit   *walk.LineEdit
+	outputDirEdit        *walk.LineEdit
+	outputDirButton      *widget.MPushButton
+	saveRangeButton      *widget.MPushButton
+	rangeStartButton     *widget.MPushButton
+	rangeEndButton       *widget.MPushButton
+	rangeStartEdit       *walk.NumberEdit
+	rangeEndEdit         *walk.NumberEdit
+	saveRetimeButton     *widget.MPushButton
+	retimeModeCombo      *walk.ComboBox
+	retimeFactorEdit     *walk.NumberEdit
+	retimeIntervalEdit   *walk.NumberEdit
+	retimeLossyCheck     *walk.CheckBox
+	saveReduceButton     *widget.MPushButton
+	reduceAngleEdit      *walk.NumberEdit
+	reducePositionEdit   *walk.NumberEdit
+	reduceMorphEdit      *walk.NumberEdit
+	reduceFitCheck       *walk.CheckBox
+	poseImportButton     *widget.MPushButton
+	poseFolderButton     *widget.MPushButton
+	poseSpacingEdit      *walk.NumberEdit
+	posePresetCombo      *walk.ComboBox
+	outputCollisionCombo *walk.ComboBox
+	// outputApplied は保存済みの出力設定を画面に反映し終えたかを表す。反映前の変更通知では保存しない。
+	outputApplied bool
 
 	modelPath  string
 	motionPath string
 	modelData  *model.PmxModel
 	motionData *motion.VmdMotion
+	aliases    *minteractor.AliasDictionary
+	profiles   []minteractor.BoneProfile
+	weights    minteractor.ScoreWeights
 }
 
 // newMotionViewerState は画面状態を初期化する。
@@ -53,10 +126,13 @@ func newMotionViewerState(translator i18n.II18n, logger logging.ILogger, userCon
 		logger = logging.DefaultLogger()
 	}
 	return &motionViewerState{
-		translator: translator,
-		logger:     logger,
-		userConfig: userConfig,
-		usecase:    viewerUsecase,
+		translator:  translator,
+		logger:      logger,
+		userConfig:  userConfig,
+		usecase:     viewerUsecase,
+		profiles:    minteractor.BuiltinBoneProfiles(),
+		weights:     minteractor.DefaultScoreWeights(),
+		stripChecks: make([]*walk.CheckBox, len(minteractor.StripKinds)),
 	}
 }
 
@@ -71,6 +147,22 @@ func (s *motionViewerState) applyInitialPaths(initialMotionPath string) {
 			s.modelPicker.SetPath(values[0])
 		}
 	}
+	if s.userConfig != nil {
+		values, err := s.userConfig.GetStringSlice(userConfigKeyAliasPaths)
+		if err == nil && len(values) > 0 {
+			s.loadAliasDictionaries(values)
+		}
+		values, err = s.userConfig.GetStringSlice(userConfigKeyProfilePaths)
+		if err == nil && len(values) > 0 {
+			s.loadBoneProfiles(values)
+		}
+		values, err = s.userConfig.GetStringSlice(userConfigKeyWeightsPath)
+		if err == nil && len(values) > 0 {
+			s.loadScoreWeights(values[0])
+		}
+	}
+	s.applyStripOperations()
+	s.applyOutputSettings()
 	if s.motionPicker != nil && initialMotionPath != "" {
 		s.motionPicker.SetPath(initialMotionPath)
 	}
@@ -171,6 +263,9 @@ func (s *motionViewerState) updatePlayerStateWithFrame(motionData *motion.VmdMot
 		maxFrame = motionData.MaxFrame()
 	}
 	s.player.Reset(maxFrame)
+	if s.rangeEndEdit != nil {
+		_ = s.rangeEndEdit.SetValue(float64(maxFrame))
+	}
 	if motionData.IsVpd() {
 		s.player.SetPlaying(false)
 		return
@@ -183,7 +278,7 @@ func (s *motionViewerState) updateCheckLists() {
 	if s == nil {
 		return
 	}
-	result, err := minteractor.CheckExists(s.modelData, s.motionData)
+	result, err := minteractor.CheckExistsWithOptions(s.modelData, s.motionData, s.checkOptions())
 	if err != nil {
 		if s.logger != nil {
 			s.logger.Error("OK/NG判定に失敗しました: %s", err.Error())
@@ -191,33 +286,241 @@ func (s *motionViewerState) updateCheckLists() {
 		return
 	}
 	if s.okBoneList != nil {
-		if err := s.okBoneList.SetItems(result.OkBones); err != nil {
+		if err := s.okBoneList.SetItems(s.formatTrackItems(result.OkBones)); err != nil {
 			if s.logger != nil {
 				s.logger.Error("OKボーン一覧の更新に失敗しました: %s", err.Error())
 			}
 		}
 	}
 	if s.okMorphList != nil {
-		if err := s.okMorphList.SetItems(result.OkMorphs); err != nil {
+		if err := s.okMorphList.SetItems(s.formatMorphGroupItems(result.OkMorphGroups, nil)); err != nil {
 			if s.logger != nil {
 				s.logger.Error("OKモーフ一覧の更新に失敗しました: %s", err.Error())
 			}
 		}
 	}
 	if s.ngBoneList != nil {
-		if err := s.ngBoneList.SetItems(result.NgBones); err != nil {
+		if err := s.ngBoneList.SetItems(s.formatNgItems(result.NgBones, result.NgBoneSuggestions)); err != nil {
 			if s.logger != nil {
 				s.logger.Error("NGボーン一覧の更新に失敗しました: %s", err.Error())
 			}
 		}
 	}
 	if s.ngMorphList != nil {
-		if err := s.ngMorphList.SetItems(result.NgMorphs); err != nil {
+		if err := s.ngMorphList.SetItems(s.formatMorphGroupItems(result.NgMorphGroups, result.NgMorphSuggestions)); err != nil {
 			if s.logger != nil {
 				s.logger.Error("NGモーフ一覧の更新に失敗しました: %s", err.Error())
 			}
 		}
 	}
+	if s.mappedBoneList != nil {
+		items := append(s.formatMappedItems(result.MappedBones, ""), s.formatMappedItems(result.EnglishBones, mappedTagEnglish)...)
+		items = append(items, s.formatMappedItems(result.TruncatedBones, mappedTagTruncated)...)
+		items = append(items, s.formatAmbiguousItems(result.AmbiguousBones)...)
+		if err := s.mappedBoneList.SetItems(items); err != nil {
+			if s.logger != nil {
+				s.logger.Error("対応付けボーン一覧の更新に失敗しました: %s", err.Error())
+			}
+		}
+	}
+	if s.mappedMorphList != nil {
+		items := append(s.formatMappedItems(result.MappedMorphs, ""), s.formatMappedItems(result.EnglishMorphs, mappedTagEnglish)...)
+		items = append(items, s.formatMappedItems(result.TruncatedMorphs, mappedTagTruncated)...)
+		items = append(items, s.formatAmbiguousItems(result.AmbiguousMorphs)...)
+		if err := s.mappedMorphList.SetItems(items); err != nil {
+			if s.logger != nil {
+				s.logger.Error("対応付けモーフ一覧の更新に失敗しました: %s", err.Error())
+			}
+		}
+	}
+	if s.auditList != nil {
+		if err := s.auditList.SetItems(s.formatAuditItems(result)); err != nil {
+			if s.logger != nil {
+				s.logger.Error("監査結果一覧の更新に失敗しました: %s", err.Error())
+			}
+		}
+	}
+	if s.lipSyncList != nil {
+		if err := s.lipSyncList.SetItems(s.formatLipSyncItems(result.LipSyncAudit)); err != nil {
+			if s.logger != nil {
+				s.logger.Error("リップシンク監査結果一覧の更新に失敗しました: %s", err.Error())
+			}
+		}
+	}
+	if s.ikList != nil {
+		if err := s.ikList.SetItems(s.formatIkItems(result.IkBones)); err != nil {
+			if s.logger != nil {
+				s.logger.Error("IK切替一覧の更新に失敗しました: %s", err.Error())
+			}
+		}
+	}
+	if s.scoreLabel != nil {
+		score := minteractor.ScoreCompatibility(result, s.weights)
+		if err := s.scoreLabel.SetText(s.formatScore(score)); err != nil {
+			if s.logger != nil {
+				s.logger.Error("互換スコアの更新に失敗しました: %s", err.Error())
+			}
+		}
+	}
+}
+
+// formatScore は互換スコアを「スコア: 点 (減点要因 -点, ...)」の表示文字列にする。
+func (s *motionViewerState) formatScore(score minteractor.CompatibilityScore) string {
+	text := fmt.Sprintf("%s: %.1f", i18n.TranslateOrMark(s.translator, messages.LabelScore), score.Score)
+	if len(score.Contributors) == 0 {
+		return text
+	}
+	parts := make([]string, 0, len(score.Contributors))
+	for _, contributor := range score.Contributors {
+		parts = append(parts, fmt.Sprintf("%s -%.1f", contributor.Name, contributor.Penalty))
+	}
+	return text + "  (" + strings.Join(parts, ", ") + ")"
+}
+
+// formatAuditItems は監査結果を「名前 (プロファイル): 影響」の表示文字列にする。
+func (s *motionViewerState) formatAuditItems(result minteractor.CheckResult) []string {
+	if len(result.ProfileAudit) == 0 {
+		return nil
+	}
+	items := make([]string, 0, len(result.ProfileAudit))
+	for _, entry := range result.ProfileAudit {
+		effect := string(entry.Effect)
+		if minteractor.IsBuiltinBoneEffect(entry.Effect) {
+			effect = i18n.TranslateOrMark(s.translator, effect)
+		}
+		items = append(items, fmt.Sprintf("%s (%s): %s  %s",
+			entry.Name, entry.Profile, effect, s.formatTrackStats(entry.TrackStats)))
+	}
+	return items
+}
+
+// formatLipSyncItems はリップシンク監査結果を「名前: 影響」の表示文字列にする。
+func (s *motionViewerState) formatLipSyncItems(entries []minteractor.LipSyncAuditEntry) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	items := make([]string, len(entries))
+	for i, entry := range entries {
+		items[i] = fmt.Sprintf("%s: %s  %s", entry.Name,
+			i18n.TranslateOrMark(s.translator, string(entry.Effect)), s.formatTrackStats(entry.TrackStats))
+	}
+	return items
+}
+
+// formatIkItems はIK切替の判定結果を「[OK/NG] 名前: 状態 [切替フレーム]」の表示文字列にする。
+func (s *motionViewerState) formatIkItems(entries []minteractor.IkFrameEntry) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	items := make([]string, len(entries))
+	for i, entry := range entries {
+		mark := "NG"
+		if entry.IsOk() {
+			mark = "OK"
+		}
+		items[i] = fmt.Sprintf("[%s] %s: %s  [%s]", mark, entry.Name,
+			i18n.TranslateOrMark(s.translator, string(entry.Status)), minteractor.FormatIkToggles(entry.Toggles))
+	}
+	return items
+}
+
+// formatMorphGroupItems はモーフ一覧を操作パネル区分順に「[区分] 名前」の表示文字列にする。
+// suggestions を指定した場合はNGとして最有力候補を併記する。
+func (s *motionViewerState) formatMorphGroupItems(groups []minteractor.MorphPanelGroup, suggestions map[string][]minteractor.NameSuggestion) []string {
+	items := make([]string, 0)
+	for _, group := range groups {
+		label := i18n.TranslateOrMark(s.translator, string(group.Group))
+		groupItems := s.formatTrackItems(group.Entries)
+		if suggestions != nil {
+			groupItems = s.formatNgItems(group.Entries, suggestions)
+		}
+		for _, item := range groupItems {
+			items = append(items, fmt.Sprintf("[%s] %s", label, item))
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
+
+// checkOptions は現在の画面状態からOK/NG判定の追加解決手段を組み立てる。
+func (s *motionViewerState) checkOptions() minteractor.CheckOptions {
+	return minteractor.CheckOptions{
+		Aliases:          s.aliases,
+		MatchEnglishName: s.matchEnglishCheck != nil && s.matchEnglishCheck.Checked(),
+		Profiles:         s.profiles,
+	}
+}
+
+// formatTrackItems はトラック一覧を統計付きの表示文字列にする。
+func (s *motionViewerState) formatTrackItems(entries []minteractor.TrackEntry) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	items := make([]string, len(entries))
+	for i, entry := range entries {
+		items[i] = fmt.Sprintf("%s  %s", entry.Name, s.formatTrackStats(entry.TrackStats))
+	}
+	return items
+}
+
+// formatMappedItems は対応付け結果を「名前 ⇒ 対応先 [種別]」の表示文字列にする。
+func (s *motionViewerState) formatMappedItems(entries []minteractor.MappedName, tag string) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	items := make([]string, len(entries))
+	for i, entry := range entries {
+		stats := s.formatTrackStats(entry.TrackStats)
+		if tag == "" {
+			items[i] = fmt.Sprintf("%s ⇒ %s  %s", entry.Name, entry.Target, stats)
+			continue
+		}
+		items[i] = fmt.Sprintf("%s ⇒ %s [%s]  %s", entry.Name, entry.Target, tag, stats)
+	}
+	return items
+}
+
+// formatAmbiguousItems は曖昧な切り詰め一致を「名前 ⇒ 候補1 | 候補2 [曖昧]」の表示文字列にする。
+func (s *motionViewerState) formatAmbiguousItems(entries []minteractor.AmbiguousName) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	tag := i18n.TranslateOrMark(s.translator, messages.LabelAmbiguousTag)
+	items := make([]string, len(entries))
+	for i, entry := range entries {
+		items[i] = fmt.Sprintf("%s ⇒ %s [%s]  %s", entry.Name, strings.Join(entry.Candidates, " | "), tag,
+			s.formatTrackStats(entry.TrackStats))
+	}
+	return items
+}
+
+// formatNgItems はNG名に最有力候補と統計を併記した表示文字列を生成する。
+func (s *motionViewerState) formatNgItems(entries []minteractor.TrackEntry, suggestions map[string][]minteractor.NameSuggestion) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	items := make([]string, len(entries))
+	for i, entry := range entries {
+		stats := s.formatTrackStats(entry.TrackStats)
+		suggestion, ok := minteractor.TopSuggestion(suggestions, entry.Name)
+		if !ok {
+			items[i] = fmt.Sprintf("%s  %s", entry.Name, stats)
+			continue
+		}
+		items[i] = fmt.Sprintf("%s → %s  %s", entry.Name, suggestion.Name, stats)
+	}
+	return items
+}
+
+// formatTrackStats は「[キー数 | 開始-終了 | 固定]」形式の統計表示を返す。
+func (s *motionViewerState) formatTrackStats(stats minteractor.TrackStats) string {
+	if stats.Constant {
+		return fmt.Sprintf("[%d | %v-%v | %s]", stats.KeyCount, stats.FirstFrame, stats.LastFrame,
+			i18n.TranslateOrMark(s.translator, messages.LabelTrackConstant))
+	}
+	return fmt.Sprintf("[%d | %v-%v]", stats.KeyCount, stats.FirstFrame, stats.LastFrame)
 }
 
 // saveModelSetting は設定保存のログ出力のみを行う。
@@ -248,10 +551,14 @@ func (s *motionViewerState) saveSafeMotion() {
 		controller.Beep()
 		return
 	}
-	result, err := s.usecase.SaveSafeMotion(minteractor.SafeMotionSaveRequest{
-		Motion:       s.motionData,
-		FallbackPath: s.motionPath,
-	})
+	request := s.safeMotionRequest(false)
+	if len(request.Operations) == 0 {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogSafeSaveFailure), nil)
+		logInfoLine(s.logger, messages.LogSafeNoOperation)
+		controller.Beep()
+		return
+	}
+	result, err := s.usecase.SaveSafeMotion(request)
 	basePath := ""
 	safePath := ""
 	if result != nil {
@@ -270,8 +577,1094 @@ func (s *motionViewerState) saveSafeMotion() {
 		controller.Beep()
 		return
 	}
+	if result.OutputSkipped {
+		s.logOutputSkipped(safePath)
+		return
+	}
 
 	logInfoLine(s.logger, messages.LogSafeSaveSuccess)
 	logInfoLine(s.logger, messages.LogSafeSaveSuccessDetail, safePath)
+	s.logIkBakeResult(result.IkBake)
+	controller.Beep()
+}
+
+// previewSafeMotion はIK無効モーションを保存せずに、保存した場合の差分を出力する。
+func (s *motionViewerState) previewSafeMotion() {
+	if s == nil || s.motionData == nil {
+		return
+	}
+	if s.usecase == nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogSafeDiffFailure), nil)
+		controller.Beep()
+		return
+	}
+	request := s.safeMotionRequest(true)
+	if len(request.Operations) == 0 {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogSafeDiffFailure), nil)
+		logInfoLine(s.logger, messages.LogSafeNoOperation)
+		controller.Beep()
+		return
+	}
+	result, err := s.usecase.SaveSafeMotion(request)
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogSafeDiffFailure), err)
+		controller.Beep()
+		return
+	}
+	if result == nil || result.Diff == nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogSafeDiffFailure), nil)
+		controller.Beep()
+		return
+	}
+
+	logInfoLine(s.logger, messages.LogSafeDiff)
+	logInfoLine(s.logger, messages.LogSafeDiffPath, result.SafePath)
+	if result.OutputSkipped {
+		logInfoLine(s.logger, messages.LogOutputSkippedDetail, result.SafePath)
+	}
+	s.logIkBakeResult(result.IkBake)
+	if !result.Diff.HasChanges() {
+		logInfoLine(s.logger, messages.LogSafeDiffNone)
+		controller.Beep()
+		return
+	}
+	for _, total := range result.Diff.SectionTotals() {
+		logInfoLine(s.logger, messages.LogSafeDiffSection, s.diffSectionLabel(total.Section),
+			total.Added, total.Removed, total.Changed, total.Before, total.After)
+	}
+	for _, track := range result.Diff.Tracks {
+		if track.Name == "" {
+			continue
+		}
+		logInfoLine(s.logger, messages.LogSafeDiffTrack, s.diffSectionLabel(track.Section), track.Name,
+			track.Added, track.Removed, track.Changed)
+	}
+	controller.Beep()
+}
+
+// logIkBakeResult はIKの焼き込みを行った場合に、焼き込んだフレーム数・最大誤差・ボーンを出力する。
+func (s *motionViewerState) logIkBakeResult(result *minteractor.IkBakeResult) {
+	if result == nil {
+		return
+	}
+	logInfoLine(s.logger, messages.LogSafeIkBake, result.FrameCount, result.MaxError,
+		len(result.BakedBones), strings.Join(result.BakedBones, ", "))
+}
+
+// safeMotionRequest は画面の設定から安全モーション保存の入力を組み立て、除去操作を記憶する。
+func (s *motionViewerState) safeMotionRequest(dryRun bool) minteractor.SafeMotionSaveRequest {
+	operations := s.stripOperations()
+	s.saveStripOperations(operations)
+	return minteractor.SafeMotionSaveRequest{
+		Motion:       s.motionData,
+		FallbackPath: s.motionPath,
+		Operations:   operations,
+		Sanitize: minteractor.SanitizeContext{
+			Model:        s.modelData,
+			CheckOptions: s.checkOptions(),
+		},
+		Output: s.outputOptions(),
+		DryRun: dryRun,
+	}
+}
+
+// diffSectionLabel は差分の区分の表示名を返す。
+func (s *motionViewerState) diffSectionLabel(section minteractor.DiffSection) string {
+	key, ok := diffSectionLabelKeys[section]
+	if !ok {
+		return string(section)
+	}
+	return i18n.TranslateOrMark(s.translator, key)
+}
+
+// saveRangeMotion は指定した区間を0フレームに詰めたモーションとして保存する。
+func (s *motionViewerState) saveRangeMotion() {
+	if s == nil || s.motionData == nil {
+		return
+	}
+	if s.usecase == nil || s.rangeStartEdit == nil || s.rangeEndEdit == nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogRangeSaveFailure), nil)
+		controller.Beep()
+		return
+	}
+	start := motion.Frame(s.rangeStartEdit.Value())
+	end := motion.Frame(s.rangeEndEdit.Value())
+	result, err := s.usecase.SaveRangeMotion(minteractor.RangeMotionSaveRequest{
+		Motion:       s.motionData,
+		FallbackPath: s.motionPath,
+		Start:        start,
+		End:          end,
+		Output:       s.outputOptions(),
+	})
+	basePath := ""
+	rangePath := ""
+	if result != nil {
+		basePath = result.BasePath
+		rangePath = result.RangePath
+	}
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogRangeSaveFailure), err)
+		logInfoLine(s.logger, messages.LogRangeSaveFailureDetail, rangePath)
+		controller.Beep()
+		return
+	}
+	if basePath == "" || rangePath == "" {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogRangeSaveFailure), nil)
+		logInfoLine(s.logger, messages.LogRangeSaveFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if result.OutputSkipped {
+		s.logOutputSkipped(rangePath)
+		return
+	}
+
+	logInfoLine(s.logger, messages.LogRangeSaveSuccess)
+	logInfoLine(s.logger, messages.LogRangeSaveSuccessDetail, start, end, rangePath)
+	controller.Beep()
+}
+
+// setRangeFromPlayer は再生位置を区間の開始または終了に設定する。
+func (s *motionViewerState) setRangeFromPlayer(edit *walk.NumberEdit) {
+	if s == nil || s.player == nil || edit == nil {
+		return
+	}
+	_ = edit.SetValue(float64(s.player.Frame()))
+}
+
+// saveRetimedMotion はキーの時間を伸縮または打ち直したモーションを保存する。
+// 元の動きを再現できないキーがある場合は、保存の成否にかかわらず一覧を出力する。
+func (s *motionViewerState) saveRetimedMotion() {
+	if s == nil || s.motionData == nil {
+		return
+	}
+	if s.usecase == nil || s.retimeFactorEdit == nil || s.retimeIntervalEdit == nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogRetimeSaveFailure), nil)
+		controller.Beep()
+		return
+	}
+	mode := s.retimeMode()
+	result, err := s.usecase.SaveRetimedMotion(minteractor.RetimeMotionSaveRequest{
+		Motion:       s.motionData,
+		FallbackPath: s.motionPath,
+		Retime: minteractor.RetimeOptions{
+			Mode:       mode,
+			Factor:     s.retimeFactorEdit.Value(),
+			Interval:   motion.Frame(s.retimeIntervalEdit.Value()),
+			AllowLossy: s.retimeLossyCheck != nil && s.retimeLossyCheck.Checked(),
+		},
+		Output: s.outputOptions(),
+	})
+	basePath := ""
+	retimedPath := ""
+	if result != nil {
+		basePath = result.BasePath
+		retimedPath = result.RetimedPath
+		s.logRetimeIssues(result.Retime)
+	}
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogRetimeSaveFailure), err)
+		logInfoLine(s.logger, messages.LogRetimeSaveFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if basePath == "" || retimedPath == "" {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogRetimeSaveFailure), nil)
+		logInfoLine(s.logger, messages.LogRetimeSaveFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if result.OutputSkipped {
+		s.logOutputSkipped(retimedPath)
+		return
+	}
+
+	logInfoLine(s.logger, messages.LogRetimeSaveSuccess)
+	logInfoLine(s.logger, messages.LogRetimeSaveSuccessDetail, s.retimeModeLabel(mode), retimedPath)
+	controller.Beep()
+}
+
+// logRetimeIssues は時間変換で重なったキーと欠落したキーを出力する。
+func (s *motionViewerState) logRetimeIssues(result minteractor.RetimeResult) {
+	for _, issue := range result.Collisions {
+		logInfoLine(s.logger, messages.LogRetimeCollision, s.diffSectionLabel(issue.Section), issue.Name,
+			issue.Frame, formatFrames(issue.SourceFrames))
+	}
+	for _, issue := range result.Dropped {
+		logInfoLine(s.logger, messages.LogRetimeDropped, s.diffSectionLabel(issue.Section), issue.Name,
+			issue.Frame, formatFrames(issue.SourceFrames))
+	}
+}
+
+// retimeMode は画面で選択した時間変換の種類を返す。
+func (s *motionViewerState) retimeMode() minteractor.RetimeMode {
+	if s.retimeModeCombo == nil {
+		return minteractor.RetimeStretch
+	}
+	index := s.retimeModeCombo.CurrentIndex()
+	if index < 0 || index >= len(minteractor.RetimeModes) {
+		return minteractor.RetimeStretch
+	}
+	return minteractor.RetimeModes[index]
+}
+
+// retimeModeLabel は時間変換の種類の表示名を返す。
+func (s *motionViewerState) retimeModeLabel(mode minteractor.RetimeMode) string {
+	key, ok := retimeModeLabelKeys[mode]
+	if !ok {
+		return string(mode)
+	}
+	return i18n.TranslateOrMark(s.translator, key)
+}
+
+// formatFrames はフレーム番号の一覧を「,」区切りにする。
+func formatFrames(frames []motion.Frame) string {
+	parts := make([]string, len(frames))
+	for i, frame := range frames {
+		parts[i] = fmt.Sprint(frame)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// saveReducedMotion は許容誤差内で再現できるキーを間引いたモーションを保存する。
+func (s *motionViewerState) saveReducedMotion() {
+	if s == nil || s.motionData == nil {
+		return
+	}
+	if s.usecase == nil || s.reduceAngleEdit == nil || s.reducePositionEdit == nil || s.reduceMorphEdit == nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogReduceSaveFailure), nil)
+		controller.Beep()
+		return
+	}
+	result, err := s.usecase.SaveReducedMotion(minteractor.ReduceMotionSaveRequest{
+		Motion:       s.motionData,
+		FallbackPath: s.motionPath,
+		Reduce: minteractor.ReduceOptions{
+			AngleTolerance:    s.reduceAngleEdit.Value(),
+			PositionTolerance: s.reducePositionEdit.Value(),
+			MorphTolerance:    s.reduceMorphEdit.Value(),
+			FitCurves:         s.reduceFitCheck == nil || s.reduceFitCheck.Checked(),
+		},
+		Output: s.outputOptions(),
+	})
+	basePath := ""
+	reducedPath := ""
+	if result != nil {
+		basePath = result.BasePath
+		reducedPath = result.ReducedPath
+	}
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogReduceSaveFailure), err)
+		logInfoLine(s.logger, messages.LogReduceSaveFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if basePath == "" || reducedPath == "" {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogReduceSaveFailure), nil)
+		logInfoLine(s.logger, messages.LogReduceSaveFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if result.OutputSkipped {
+		s.logOutputSkipped(reducedPath)
+		return
+	}
+
+	before, after := result.Reduce.KeyCounts()
+	logInfoLine(s.logger, messages.LogReduceSaveSuccess)
+	logInfoLine(s.logger, messages.LogReduceSaveSuccessDetail, before, after, reducedPath)
+	for _, track := range result.Reduce.Tracks {
+		if track.After == track.Before {
+			continue
+		}
+		if track.Section == minteractor.DiffSectionMorph {
+			logInfoLine(s.logger, messages.LogReduceMorph, s.diffSectionLabel(track.Section), track.Name,
+				track.Before, track.After, track.MaxValue)
+			continue
+		}
+		logInfoLine(s.logger, messages.LogReduceBone, s.diffSectionLabel(track.Section), track.Name,
+			track.Before, track.After, track.MaxAngle, track.MaxPosition)
+	}
 	controller.Beep()
 }
+
+// selectAliasDictionaries は別名辞書を選択して読み込み、選択内容を記憶する。
+func (s *motionViewerState) selectAliasDictionaries() {
+	if s == nil {
+		return
+	}
+	paths, err := selectOpenFilePaths(
+		i18n.TranslateOrMark(s.translator, messages.LabelAliasLoad),
+		"Alias dictionary (*.json;*.csv)|*.json;*.csv",
+	)
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogAliasLoadFailure), err)
+		return
+	}
+	if len(paths) == 0 {
+		return
+	}
+	if !s.loadAliasDictionaries(paths) {
+		controller.Beep()
+		return
+	}
+	s.saveConfigPaths(userConfigKeyAliasPaths, paths)
+	s.updateCheckLists()
+}
+
+// loadAliasDictionaries は指定された別名辞書をまとめて読み込む。
+func (s *motionViewerState) loadAliasDictionaries(paths []string) bool {
+	if s == nil {
+		return false
+	}
+	aliases := minteractor.NewAliasDictionary()
+	for _, path := range paths {
+		dict, err := minteractor.LoadAliasDictionary(path)
+		if err != nil {
+			logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogAliasLoadFailure), err)
+			return false
+		}
+		aliases.Merge(dict)
+		logInfoLine(s.logger, messages.LogAliasLoadSuccess, path)
+	}
+	s.aliases = aliases
+	return true
+}
+
+// selectBoneProfiles はボーンプロファイルを選択して読み込み、選択内容を記憶する。
+func (s *motionViewerState) selectBoneProfiles() {
+	if s == nil {
+		return
+	}
+	paths, err := selectOpenFilePaths(
+		i18n.TranslateOrMark(s.translator, messages.LabelProfileLoad),
+		"Bone profile (*.json)|*.json",
+	)
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogProfileLoadFailure), err)
+		return
+	}
+	if len(paths) == 0 {
+		return
+	}
+	if !s.loadBoneProfiles(paths) {
+		controller.Beep()
+		return
+	}
+	s.saveConfigPaths(userConfigKeyProfilePaths, paths)
+	s.updateCheckLists()
+}
+
+// loadBoneProfiles は組み込みプロファイルに指定ファイルのプロファイルを加えて読み込む。
+func (s *motionViewerState) loadBoneProfiles(paths []string) bool {
+	if s == nil {
+		return false
+	}
+	profiles := minteractor.BuiltinBoneProfiles()
+	for _, path := range paths {
+		loaded, err := minteractor.LoadBoneProfiles(path)
+		if err != nil {
+			logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogProfileLoadFailure), err)
+			return false
+		}
+		profiles = append(profiles, loaded...)
+		logInfoLine(s.logger, messages.LogProfileLoadSuccess, path)
+	}
+	s.profiles = profiles
+	return true
+}
+
+// selectScoreWeights は互換スコアの重み付けを選択して読み込み、選択内容を記憶する。
+func (s *motionViewerState) selectScoreWeights() {
+	if s == nil {
+		return
+	}
+	paths, err := selectOpenFilePaths(
+		i18n.TranslateOrMark(s.translator, messages.LabelWeightsLoad),
+		"Score weights (*.json)|*.json",
+	)
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogWeightsLoadFailure), err)
+		return
+	}
+	if len(paths) == 0 {
+		return
+	}
+	if !s.loadScoreWeights(paths[0]) {
+		controller.Beep()
+		return
+	}
+	s.saveConfigPaths(userConfigKeyWeightsPath, paths[:1])
+	s.updateCheckLists()
+}
+
+// loadScoreWeights は指定ファイルの重み付けを読み込む。
+func (s *motionViewerState) loadScoreWeights(path string) bool {
+	if s == nil {
+		return false
+	}
+	weights, err := minteractor.LoadScoreWeights(path)
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogWeightsLoadFailure), err)
+		return false
+	}
+	s.weights = weights
+	logInfoLine(s.logger, messages.LogWeightsLoadSuccess, path)
+	return true
+}
+
+// saveFittedMotion はモデルで再生できるトラックだけを残したモーションを保存する。
+func (s *motionViewerState) saveFittedMotion() {
+	if s == nil || s.motionData == nil {
+		return
+	}
+	if s.usecase == nil || s.modelData == nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogFitSaveFailure), nil)
+		controller.Beep()
+		return
+	}
+	result, err := s.usecase.SaveFittedMotion(minteractor.FittedMotionSaveRequest{
+		Motion:       s.motionData,
+		Model:        s.modelData,
+		FallbackPath: s.motionPath,
+		CheckOptions: s.checkOptions(),
+		Output:       s.outputOptions(),
+	})
+	basePath := ""
+	fittedPath := ""
+	if result != nil {
+		basePath = result.BasePath
+		fittedPath = result.FittedPath
+	}
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogFitSaveFailure), err)
+		logInfoLine(s.logger, messages.LogFitSaveFailureDetail, fittedPath)
+		controller.Beep()
+		return
+	}
+	if basePath == "" || fittedPath == "" {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogFitSaveFailure), nil)
+		logInfoLine(s.logger, messages.LogFitSaveFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if result.OutputSkipped {
+		s.logOutputSkipped(fittedPath)
+		return
+	}
+
+	logInfoLine(s.logger, messages.LogFitSaveSuccess)
+	logInfoLine(s.logger, messages.LogFitSaveSuccessDetail, fittedPath)
+	logInfoLine(s.logger, messages.LogFitSaveDroppedBones, len(result.DroppedBones),
+		strings.Join(minteractor.TrackNames(result.DroppedBones), ", "))
+	logInfoLine(s.logger, messages.LogFitSaveDroppedMorphs, len(result.DroppedMorphs),
+		strings.Join(minteractor.TrackNames(result.DroppedMorphs), ", "))
+	controller.Beep()
+}
+
+// saveRenamedMotion はトラック名をモデルの名前に変換したモーションを保存する。
+func (s *motionViewerState) saveRenamedMotion() {
+	if s == nil || s.motionData == nil {
+		return
+	}
+	if s.usecase == nil || s.modelData == nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogRenameSaveFailure), nil)
+		controller.Beep()
+		return
+	}
+	checkResult, err := minteractor.CheckExistsWithOptions(s.modelData, s.motionData, s.checkOptions())
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogRenameSaveFailure), err)
+		controller.Beep()
+		return
+	}
+	useSuggestions := s.renameSuggestCheck != nil && s.renameSuggestCheck.Checked()
+	result, err := s.usecase.SaveRenamedMotion(minteractor.RenameMotionSaveRequest{
+		Motion:       s.motionData,
+		FallbackPath: s.motionPath,
+		Mapping:      minteractor.BuildRenameMapping(checkResult, useSuggestions),
+		Policy:       s.renamePolicy(),
+		Output:       s.outputOptions(),
+	})
+	basePath := ""
+	renamedPath := ""
+	if result != nil {
+		basePath = result.BasePath
+		renamedPath = result.RenamedPath
+	}
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogRenameSaveFailure), err)
+		logInfoLine(s.logger, messages.LogRenameSaveFailureDetail, renamedPath)
+		controller.Beep()
+		return
+	}
+	if basePath == "" || renamedPath == "" {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogRenameSaveFailure), nil)
+		logInfoLine(s.logger, messages.LogRenameSaveFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if result.OutputSkipped {
+		s.logOutputSkipped(renamedPath)
+		return
+	}
+
+	logInfoLine(s.logger, messages.LogRenameSaveSuccess)
+	logInfoLine(s.logger, messages.LogRenameSaveSuccessDetail, renamedPath)
+	logInfoLine(s.logger, messages.LogRenameSaveRenamed, len(result.Renamed), formatRenamedTracks(result.Renamed))
+	conflicts := make([]minteractor.RenamedTrack, 0, len(result.Skipped))
+	tooLong := make([]minteractor.RenamedTrack, 0)
+	for _, track := range result.Skipped {
+		if track.TooLong {
+			tooLong = append(tooLong, track)
+		} else {
+			conflicts = append(conflicts, track)
+		}
+	}
+	if len(conflicts) > 0 {
+		logInfoLine(s.logger, messages.LogRenameSaveSkipped, len(conflicts), formatRenamedTracks(conflicts))
+	}
+	if len(tooLong) > 0 {
+		logInfoLine(s.logger, messages.LogRenameSaveTooLong, len(tooLong), formatRenamedTracks(tooLong))
+	}
+	controller.Beep()
+}
+
+// saveMirroredMotion は左右を入れ替えて反転したモーションを保存する。
+func (s *motionViewerState) saveMirroredMotion() {
+	if s == nil || s.motionData == nil {
+		return
+	}
+	if s.usecase == nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogMirrorSaveFailure), nil)
+		controller.Beep()
+		return
+	}
+	result, err := s.usecase.SaveMirroredMotion(minteractor.MirrorMotionSaveRequest{
+		Motion:       s.motionData,
+		FallbackPath: s.motionPath,
+		Model:        s.modelData,
+		Output:       s.outputOptions(),
+	})
+	basePath := ""
+	mirrorPath := ""
+	if result != nil {
+		basePath = result.BasePath
+		mirrorPath = result.MirrorPath
+	}
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogMirrorSaveFailure), err)
+		logInfoLine(s.logger, messages.LogMirrorSaveFailureDetail, mirrorPath)
+		controller.Beep()
+		return
+	}
+	if basePath == "" || mirrorPath == "" {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogMirrorSaveFailure), nil)
+		logInfoLine(s.logger, messages.LogMirrorSaveFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if result.OutputSkipped {
+		s.logOutputSkipped(mirrorPath)
+		return
+	}
+
+	logInfoLine(s.logger, messages.LogMirrorSaveSuccess)
+	logInfoLine(s.logger, messages.LogMirrorSaveSuccessDetail, mirrorPath)
+	logInfoLine(s.logger, messages.LogMirrorSaveSwapped, len(result.Swapped), formatRenamedTracks(result.Swapped))
+	if len(result.Unpaired) > 0 {
+		logInfoLine(s.logger, messages.LogMirrorSaveUnpaired, len(result.Unpaired), formatRenamedTracks(result.Unpaired))
+	}
+	controller.Beep()
+}
+
+// exportPose はプレイヤーの現在フレームのポーズをVPDとして保存する。
+func (s *motionViewerState) exportPose() {
+	if s == nil || s.motionData == nil || s.player == nil {
+		return
+	}
+	if s.usecase == nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogPoseExportFailure), nil)
+		controller.Beep()
+		return
+	}
+	frame := s.player.Frame()
+	result, err := s.usecase.ExportPose(minteractor.PoseExportRequest{
+		Motion:       s.motionData,
+		FallbackPath: s.motionPath,
+		Frame:        frame,
+		Output:       s.outputOptions(),
+	})
+	basePath := ""
+	posePath := ""
+	if result != nil {
+		basePath = result.BasePath
+		posePath = result.PosePath
+	}
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogPoseExportFailure), err)
+		logInfoLine(s.logger, messages.LogPoseExportFailureDetail, posePath)
+		controller.Beep()
+		return
+	}
+	if basePath == "" || posePath == "" {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogPoseExportFailure), nil)
+		logInfoLine(s.logger, messages.LogPoseExportFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if result.OutputSkipped {
+		s.logOutputSkipped(posePath)
+		return
+	}
+
+	logInfoLine(s.logger, messages.LogPoseExportSuccess)
+	logInfoLine(s.logger, messages.LogPoseExportSuccessDetail, frame, result.Bones, result.Morphs, posePath)
+	controller.Beep()
+}
+
+// exportCsv はモーションの全キーフレームをCSVとして保存する。
+func (s *motionViewerState) exportCsv() {
+	if s == nil || s.motionData == nil {
+		return
+	}
+	if s.usecase == nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogCsvExportFailure), nil)
+		controller.Beep()
+		return
+	}
+	result, err := s.usecase.ExportCsv(minteractor.CsvExportRequest{
+		Motion:       s.motionData,
+		FallbackPath: s.motionPath,
+		Output:       s.outputOptions(),
+	})
+	basePath := ""
+	csvPath := ""
+	if result != nil {
+		basePath = result.BasePath
+		csvPath = result.CsvPath
+	}
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogCsvExportFailure), err)
+		logInfoLine(s.logger, messages.LogCsvExportFailureDetail, csvPath)
+		controller.Beep()
+		return
+	}
+	if basePath == "" || csvPath == "" {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogCsvExportFailure), nil)
+		logInfoLine(s.logger, messages.LogCsvExportFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if result.OutputSkipped {
+		s.logOutputSkipped(csvPath)
+		return
+	}
+
+	logInfoLine(s.logger, messages.LogCsvExportSuccess)
+	logInfoLine(s.logger, messages.LogCsvExportSuccessDetail, csvPath)
+	controller.Beep()
+}
+
+// selectCsvMotion はキーフレームのCSVを選択し、モーションとして読み込む。
+func (s *motionViewerState) selectCsvMotion() {
+	if s == nil || s.motionPicker == nil {
+		return
+	}
+	paths, err := selectOpenFilePaths(
+		i18n.TranslateOrMark(s.translator, messages.LabelCsvLoad),
+		"Motion CSV (*.csv)|*.csv",
+	)
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, "読み込み失敗"), err)
+		return
+	}
+	if len(paths) == 0 {
+		return
+	}
+	// ファイル選択と同じく履歴と読み込みはモーションのファイル選択に任せる。
+	s.motionPicker.SetPath(paths[0])
+}
+
+// selectPoseFiles はVPDファイルを選択し、ファイル名の自然順に並べて1つのモーションに変換する。
+func (s *motionViewerState) selectPoseFiles() {
+	if s == nil {
+		return
+	}
+	paths, err := selectOpenFilePaths(
+		i18n.TranslateOrMark(s.translator, messages.LabelPoseImport),
+		"Vocaloid Pose Data (*.vpd)|*.vpd",
+	)
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogPoseImportFailure), err)
+		return
+	}
+	if len(paths) == 0 {
+		return
+	}
+	// ダイアログの選択順は一定ではないため、ファイル名の自然順（pose2 → pose10）にそろえる。
+	minteractor.SortPathsNatural(paths)
+	s.importPoses(paths)
+}
+
+// selectPoseFolder はフォルダを選択し、直下のVPDファイルを1つのモーションに変換する。
+func (s *motionViewerState) selectPoseFolder() {
+	if s == nil {
+		return
+	}
+	dlg := new(walk.FileDialog)
+	dlg.Title = i18n.TranslateOrMark(s.translator, messages.LabelPoseFolder)
+	accepted, err := dlg.ShowBrowseFolder(nil)
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogPoseImportFailure), err)
+		return
+	}
+	if !accepted || dlg.FilePath == "" {
+		return
+	}
+	s.importPoses([]string{dlg.FilePath})
+}
+
+// importPoses はVPDポーズを画面の間隔と補間曲線でキーにしたモーションを保存する。
+func (s *motionViewerState) importPoses(paths []string) {
+	if s.usecase == nil || s.poseSpacingEdit == nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogPoseImportFailure), nil)
+		controller.Beep()
+		return
+	}
+	result, err := s.usecase.ImportPoses(minteractor.PoseImportRequest{
+		PosePaths: paths,
+		Sequence: minteractor.PoseSequenceOptions{
+			Spacing: motion.Frame(s.poseSpacingEdit.Value()),
+			Preset:  s.posePreset(),
+		},
+		Output: s.outputOptions(),
+	})
+	basePath := strings.Join(paths, ", ")
+	motionPath := ""
+	if result != nil {
+		if result.BasePath != "" {
+			basePath = result.BasePath
+		}
+		motionPath = result.MotionPath
+	}
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogPoseImportFailure), err)
+		logInfoLine(s.logger, messages.LogPoseImportFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if motionPath == "" {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogPoseImportFailure), nil)
+		logInfoLine(s.logger, messages.LogPoseImportFailureDetail, basePath)
+		controller.Beep()
+		return
+	}
+	if result.OutputSkipped {
+		s.logOutputSkipped(motionPath)
+		return
+	}
+
+	logInfoLine(s.logger, messages.LogPoseImportSuccess)
+	logInfoLine(s.logger, messages.LogPoseImportSuccessDetail, len(result.PosePaths), result.Bones, result.Morphs, motionPath)
+	controller.Beep()
+}
+
+// posePreset は画面で選択した補間曲線の種類を返す。
+func (s *motionViewerState) posePreset() minteractor.InterpolationPreset {
+	if s.posePresetCombo == nil {
+		return minteractor.InterpolationLinear
+	}
+	index := s.posePresetCombo.CurrentIndex()
+	if index < 0 || index >= len(minteractor.InterpolationPresets) {
+		return minteractor.InterpolationLinear
+	}
+	return minteractor.InterpolationPresets[index]
+}
+
+// renamePolicy は画面で選択した重複時の扱いを返す。
+func (s *motionViewerState) renamePolicy() minteractor.RenameConflictPolicy {
+	if s.renamePolicyCombo == nil {
+		return minteractor.RenameSkip
+	}
+	index := s.renamePolicyCombo.CurrentIndex()
+	if index < 0 || index >= len(minteractor.RenameConflictPolicies) {
+		return minteractor.RenameSkip
+	}
+	return minteractor.RenameConflictPolicies[index]
+}
+
+// formatRenamedTracks は変換したトラックを「変換元 ⇒ 変換先」の列挙にする。
+func formatRenamedTracks(tracks []minteractor.RenamedTrack) string {
+	parts := make([]string, len(tracks))
+	for i, track := range tracks {
+		parts[i] = track.From + " ⇒ " + track.To
+	}
+	return strings.Join(parts, ", ")
+}
+
+// applyStripOperations はユーザー設定に保存した除去操作を画面に反映する。
+// 未保存の場合はIKフレームの除去のみを選択し、何も選択せずに保存した場合は全て外す。
+func (s *motionViewerState) applyStripOperations() {
+	if s == nil {
+		return
+	}
+	operations := minteractor.DefaultStripOperations()
+	if s.userConfig != nil {
+		values, err := s.userConfig.GetStringSlice(userConfigKeyStripOps)
+		if err == nil && len(values) > 0 {
+			parsed, err := minteractor.ParseStripOperations(values)
+			if err != nil {
+				if s.logger != nil {
+					s.logger.Error("除去操作の設定の読み込みに失敗しました: %s", err.Error())
+				}
+			} else {
+				operations = parsed
+			}
+		}
+	}
+	selected := make(map[minteractor.StripKind]minteractor.StripOperation, len(operations))
+	for _, operation := range operations {
+		selected[operation.Kind] = operation
+	}
+	for i, kind := range minteractor.StripKinds {
+		if i >= len(s.stripChecks) || s.stripChecks[i] == nil {
+			continue
+		}
+		_, ok := selected[kind]
+		s.stripChecks[i].SetChecked(ok)
+	}
+	if s.bakeEveryCheck != nil {
+		s.bakeEveryCheck.SetChecked(selected[minteractor.StripBakeIk].EveryFrame)
+	}
+	if s.stripNamesEdit != nil {
+		_ = s.stripNamesEdit.SetText(strings.Join(selected[minteractor.StripNames].Names, ", "))
+	}
+	if s.stripPatternEdit != nil {
+		_ = s.stripPatternEdit.SetText(selected[minteractor.StripPattern].Pattern)
+	}
+}
+
+// stripOperations は画面で選択した除去操作を組み立てる。
+func (s *motionViewerState) stripOperations() []minteractor.StripOperation {
+	operations := make([]minteractor.StripOperation, 0, len(minteractor.StripKinds))
+	for i, kind := range minteractor.StripKinds {
+		if i >= len(s.stripChecks) || s.stripChecks[i] == nil || !s.stripChecks[i].Checked() {
+			continue
+		}
+		operation := minteractor.StripOperation{Kind: kind}
+		switch kind {
+		case minteractor.StripBakeIk:
+			operation.EveryFrame = s.bakeEveryCheck != nil && s.bakeEveryCheck.Checked()
+		case minteractor.StripNames:
+			if s.stripNamesEdit != nil {
+				operation.Names = minteractor.SplitStripNames(s.stripNamesEdit.Text())
+			}
+		case minteractor.StripPattern:
+			if s.stripPatternEdit != nil {
+				operation.Pattern = strings.TrimSpace(s.stripPatternEdit.Text())
+			}
+		}
+		operations = append(operations, operation)
+	}
+	return operations
+}
+
+// saveStripOperations は選択した除去操作をユーザー設定に保存する。
+func (s *motionViewerState) saveStripOperations(operations []minteractor.StripOperation) {
+	if s == nil || s.userConfig == nil {
+		return
+	}
+	values := minteractor.FormatStripOperations(operations)
+	if err := s.userConfig.SetStringSlice(userConfigKeyStripOps, values, len(minteractor.StripKinds)); err != nil {
+		if s.logger != nil {
+			s.logger.Error("除去操作の設定の保存に失敗しました: %s", err.Error())
+		}
+	}
+}
+
+// applyOutputSettings はユーザー設定に保存した出力設定を画面に反映する。
+func (s *motionViewerState) applyOutputSettings() {
+	if s == nil || s.userConfig == nil {
+		return
+	}
+	defer func() { s.outputApplied = true }()
+	values, err := s.userConfig.GetStringSlice(userConfigKeyOutput)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Error("出力設定の読み込みに失敗しました: %s", err.Error())
+		}
+		return
+	}
+	for _, value := range values {
+		key, argument, _ := strings.Cut(value, "=")
+		switch key {
+		case outputSettingTemplate:
+			if s.outputTemplateEdit != nil && argument != "" {
+				_ = s.outputTemplateEdit.SetText(argument)
+			}
+		case outputSettingDir:
+			if s.outputDirEdit != nil {
+				_ = s.outputDirEdit.SetText(argument)
+			}
+		case outputSettingCollision:
+			if s.outputCollisionCombo == nil {
+				continue
+			}
+			for i, policy := range minteractor.CollisionPolicies {
+				if string(policy) == argument {
+					_ = s.outputCollisionCombo.SetCurrentIndex(i)
+					break
+				}
+			}
+		}
+	}
+}
+
+// outputOptions は画面で指定した出力設定を組み立てる。
+func (s *motionViewerState) outputOptions() minteractor.OutputPathOptions {
+	options := minteractor.OutputPathOptions{
+		Collision: minteractor.CollisionOverwrite,
+		ConfirmOverwrite: func(path string) bool {
+			message := fmt.Sprintf(i18n.TranslateOrMark(s.translator, messages.LabelOverwriteConfirmTip), path)
+			return walk.MsgBox(nil, i18n.TranslateOrMark(s.translator, messages.LabelOverwriteConfirm), message,
+				walk.MsgBoxYesNo|walk.MsgBoxIconQuestion) == walk.DlgCmdYes
+		},
+	}
+	if s.outputTemplateEdit != nil {
+		options.Template = strings.TrimSpace(s.outputTemplateEdit.Text())
+	}
+	if s.outputDirEdit != nil {
+		options.Dir = strings.TrimSpace(s.outputDirEdit.Text())
+	}
+	if s.outputCollisionCombo != nil {
+		index := s.outputCollisionCombo.CurrentIndex()
+		if index >= 0 && index < len(minteractor.CollisionPolicies) {
+			options.Collision = minteractor.CollisionPolicies[index]
+		}
+	}
+	if s.modelData != nil {
+		options.ModelName = s.modelData.Name()
+	}
+	return options
+}
+
+// saveOutputSettings は画面の出力設定をユーザー設定に保存する。出力設定の入力欄の変更時に呼ぶ。
+func (s *motionViewerState) saveOutputSettings() {
+	if s == nil || s.userConfig == nil || !s.outputApplied {
+		return
+	}
+	options := s.outputOptions()
+	values := []string{
+		outputSettingTemplate + "=" + options.Template,
+		outputSettingDir + "=" + options.Dir,
+		outputSettingCollision + "=" + string(options.Collision),
+	}
+	if err := s.userConfig.SetStringSlice(userConfigKeyOutput, values, len(values)); err != nil {
+		if s.logger != nil {
+			s.logger.Error("出力設定の保存に失敗しました: %s", err.Error())
+		}
+	}
+}
+
+// selectOutputDir はフォルダ選択ダイアログで出力先を選ぶ。
+func (s *motionViewerState) selectOutputDir() {
+	if s == nil || s.outputDirEdit == nil {
+		return
+	}
+	dlg := new(walk.FileDialog)
+	dlg.Title = i18n.TranslateOrMark(s.translator, messages.LabelOutputDir)
+	dlg.FilePath = s.outputDirEdit.Text()
+	accepted, err := dlg.ShowBrowseFolder(nil)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Error("出力先の選択に失敗しました: %s", err.Error())
+		}
+		return
+	}
+	if !accepted || dlg.FilePath == "" {
+		return
+	}
+	_ = s.outputDirEdit.SetText(dlg.FilePath)
+	s.saveOutputSettings()
+}
+
+// logOutputSkipped は保存先の重複時の扱いにより保存しなかったことを出力する。
+func (s *motionViewerState) logOutputSkipped(path string) {
+	logInfoLine(s.logger, messages.LogOutputSkipped)
+	logInfoLine(s.logger, messages.LogOutputSkippedDetail, path)
+	controller.Beep()
+}
+
+// saveConfigPaths は選択したファイルパスをユーザー設定に保存する。
+func (s *motionViewerState) saveConfigPaths(key string, paths []string) {
+	if s == nil || s.userConfig == nil {
+		return
+	}
+	if err := s.userConfig.SetStringSlice(key, paths, configPathHistoryLimit); err != nil {
+		if s.logger != nil {
+			s.logger.Error("ファイルパスの保存に失敗しました: %s", err.Error())
+		}
+	}
+}
+
+// exportReport は現在のOK/NG判定結果を帳票ファイルとして保存する。
+func (s *motionViewerState) exportReport() {
+	if s == nil || s.motionData == nil {
+		return
+	}
+	path, err := selectSaveFilePath(
+		i18n.TranslateOrMark(s.translator, messages.LabelExportReport),
+		"JSON (*.json)|*.json|CSV (*.csv)|*.csv|Markdown (*.md)|*.md|HTML (*.html)|*.html",
+		buildReportPath(s.motionPath),
+		[]string{".json", ".csv", ".md", ".html"},
+	)
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogReportFailure), err)
+		controller.Beep()
+		return
+	}
+	if path == "" {
+		return
+	}
+
+	result, err := minteractor.CheckExistsWithOptions(s.modelData, s.motionData, s.checkOptions())
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogReportFailure), err)
+		controller.Beep()
+		return
+	}
+	built, err := report.Build(report.Source{
+		ModelPath:   s.modelPath,
+		MotionPath:  s.motionPath,
+		ToolName:    s.appName,
+		ToolVersion: s.appVersion,
+		Result:      result,
+		Weights:     &s.weights,
+		Translate: func(key string) string {
+			return i18n.TranslateOrMark(s.translator, key)
+		},
+	})
+	if err == nil {
+		err = report.WriteFile(path, built)
+	}
+	if err != nil {
+		logErrorWithTitle(s.logger, i18n.TranslateOrMark(s.translator, messages.LogReportFailure), err)
+		logInfoLine(s.logger, messages.LogReportFailureDetail, path)
+		controller.Beep()
+		return
+	}
+
+	logInfoLine(s.logger, messages.LogReportSuccess)
+	logInfoLine(s.logger, messages.LogReportSuccessDetail, path)
+	controller.Beep()
+}
+
+// buildReportPath は帳票の初期保存先パスを生成する。
+func buildReportPath(motionPath string) string {
+	if motionPath == "" {
+		return ""
+	}
+	ext := filepath.Ext(motionPath)
+	return strings.TrimSuffix(motionPath, ext) + "_report.html"
+}
@@ -26,11 +26,17 @@ func NewTabPages(mWidgets *controller.MWidgets, baseServices base.IBaseServices,
 	var translator i18n.II18n
 	var logger logging.ILogger
 	var userConfig config.IUserConfig
+	appName := ""
+	appVersion := ""
 	if baseServices != nil {
 		translator = baseServices.I18n()
 		logger = baseServices.Logger()
 		if cfg := baseServices.Config(); cfg != nil {
 			userConfig = cfg.UserConfig()
+			if appConfig := cfg.AppConfig(); appConfig != nil {
+				appName = appConfig.Name
+				appVersion = appConfig.Version
+			}
 		}
 	}
 	if logger == nil {
@@ -38,6 +44,8 @@ func NewTabPages(mWidgets *controller.MWidgets, baseServices base.IBaseServices,
 	}
 
 	state := newMotionViewerState(translator, logger, userConfig, viewerUsecase)
+	state.appName = appName
+	state.appVersion = appVersion
 
 	state.player = widget.NewMotionPlayer(translator)
 	state.player.SetAudioPlayer(audioPlayer, userConfig)
@@ -73,23 +81,191 @@ func NewTabPages(mWidgets *controller.MWidgets, baseServices base.IBaseServices,
 		state.saveSafeMotion()
 	})
 
+	state.previewSafeButton = widget.NewMPushButton()
+	state.previewSafeButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelSafeMotionPreview))
+	state.previewSafeButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelSafeMotionPreviewTip))
+	state.previewSafeButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.previewSafeMotion()
+	})
+
+	state.saveFitMotionButton = widget.NewMPushButton()
+	state.saveFitMotionButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelFitMotionSave))
+	state.saveFitMotionButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelFitMotionSaveTip))
+	state.saveFitMotionButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.saveFittedMotion()
+	})
+
+	state.saveRenamedButton = widget.NewMPushButton()
+	state.saveRenamedButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelRenameMotionSave))
+	state.saveRenamedButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelRenameMotionSaveTip))
+	state.saveRenamedButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.saveRenamedMotion()
+	})
+	renamePolicyLabels := make([]string, len(minteractor.RenameConflictPolicies))
+	for i, policy := range minteractor.RenameConflictPolicies {
+		renamePolicyLabels[i] = i18n.TranslateOrMark(translator, renamePolicyLabelKeys[policy])
+	}
+
+	state.saveMirrorButton = widget.NewMPushButton()
+	state.saveMirrorButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelMirrorMotionSave))
+	state.saveMirrorButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelMirrorMotionSaveTip))
+	state.saveMirrorButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.saveMirroredMotion()
+	})
+
+	state.exportPoseButton = widget.NewMPushButton()
+	state.exportPoseButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelPoseExport))
+	state.exportPoseButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelPoseExportTip))
+	state.exportPoseButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.exportPose()
+	})
+
+	state.exportCsvButton = widget.NewMPushButton()
+	state.exportCsvButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelCsvExport))
+	state.exportCsvButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelCsvExportTip))
+	state.exportCsvButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.exportCsv()
+	})
+	state.loadCsvButton = widget.NewMPushButton()
+	state.loadCsvButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelCsvLoad))
+	state.loadCsvButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelCsvLoadTip))
+	state.loadCsvButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.selectCsvMotion()
+	})
+
+	state.saveRangeButton = widget.NewMPushButton()
+	state.saveRangeButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelRangeMotionSave))
+	state.saveRangeButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelRangeMotionSaveTip))
+	state.saveRangeButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.saveRangeMotion()
+	})
+	state.rangeStartButton = widget.NewMPushButton()
+	state.rangeStartButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelRangeFromPlayer))
+	state.rangeStartButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelRangeFromPlayerTip))
+	state.rangeStartButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.setRangeFromPlayer(state.rangeStartEdit)
+	})
+	state.rangeEndButton = widget.NewMPushButton()
+	state.rangeEndButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelRangeFromPlayer))
+	state.rangeEndButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelRangeFromPlayerTip))
+	state.rangeEndButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.setRangeFromPlayer(state.rangeEndEdit)
+	})
+
+	state.saveRetimeButton = widget.NewMPushButton()
+	state.saveRetimeButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelRetimeMotionSave))
+	state.saveRetimeButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelRetimeMotionSaveTip))
+	state.saveRetimeButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.saveRetimedMotion()
+	})
+	retimeModeLabels := make([]string, len(minteractor.RetimeModes))
+	for i, mode := range minteractor.RetimeModes {
+		retimeModeLabels[i] = i18n.TranslateOrMark(translator, retimeModeLabelKeys[mode])
+	}
+
+	state.saveReduceButton = widget.NewMPushButton()
+	state.saveReduceButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelReduceMotionSave))
+	state.saveReduceButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelReduceMotionSaveTip))
+	state.saveReduceButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.saveReducedMotion()
+	})
+	reduceDefaults := minteractor.DefaultReduceOptions()
+
+	state.poseImportButton = widget.NewMPushButton()
+	state.poseImportButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelPoseImport))
+	state.poseImportButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelPoseImportTip))
+	state.poseImportButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.selectPoseFiles()
+	})
+	state.poseFolderButton = widget.NewMPushButton()
+	state.poseFolderButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelPoseFolder))
+	state.poseFolderButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelPoseImportTip))
+	state.poseFolderButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.selectPoseFolder()
+	})
+	poseDefaults := minteractor.DefaultPoseSequenceOptions()
+	posePresetLabels := make([]string, len(minteractor.InterpolationPresets))
+	for i, preset := range minteractor.InterpolationPresets {
+		posePresetLabels[i] = i18n.TranslateOrMark(translator, interpolationPresetLabelKeys[preset])
+	}
+
+	state.outputDirButton = widget.NewMPushButton()
+	state.outputDirButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelOutputDirSelect))
+	state.outputDirButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelOutputDirTip))
+	state.outputDirButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.selectOutputDir()
+	})
+	collisionLabels := make([]string, len(minteractor.CollisionPolicies))
+	for i, policy := range minteractor.CollisionPolicies {
+		collisionLabels[i] = i18n.TranslateOrMark(translator, collisionPolicyLabelKeys[policy])
+	}
+
+	state.exportReportButton = widget.NewMPushButton()
+	state.exportReportButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelExportReport))
+	state.exportReportButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelExportReportTip))
+	state.exportReportButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.exportReport()
+	})
+
+	state.loadAliasButton = widget.NewMPushButton()
+	state.loadAliasButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelAliasLoad))
+	state.loadAliasButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelAliasLoadTip))
+	state.loadAliasButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.selectAliasDictionaries()
+	})
+
+	state.loadProfileButton = widget.NewMPushButton()
+	state.loadProfileButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelProfileLoad))
+	state.loadProfileButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelProfileLoadTip))
+	state.loadProfileButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.selectBoneProfiles()
+	})
+
+	state.loadWeightsButton = widget.NewMPushButton()
+	state.loadWeightsButton.SetLabel(i18n.TranslateOrMark(translator, messages.LabelWeightsLoad))
+	state.loadWeightsButton.SetTooltip(i18n.TranslateOrMark(translator, messages.LabelWeightsLoadTip))
+	state.loadWeightsButton.SetOnClicked(func(_ *controller.ControlWindow) {
+		state.selectScoreWeights()
+	})
+
 	listMinSize := declarative.Size{Width: 220, Height: 80}
-	state.okBoneList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelOkBoneTip), logger)
+	trackStatsTip := i18n.TranslateOrMark(translator, messages.LabelTrackStatsTip)
+	state.okBoneList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelOkBoneTip)+"\n"+trackStatsTip, logger)
 	state.okBoneList.SetMinSize(listMinSize)
 	state.okBoneList.SetStretchFactor(1)
 
-	state.okMorphList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelOkMorphTip), logger)
+	state.okMorphList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelOkMorphTip)+"\n"+trackStatsTip, logger)
 	state.okMorphList.SetMinSize(listMinSize)
 	state.okMorphList.SetStretchFactor(1)
 
-	state.ngBoneList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelNgBoneTip), logger)
+	state.ngBoneList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelNgBoneTip)+"\n"+trackStatsTip, logger)
 	state.ngBoneList.SetMinSize(listMinSize)
 	state.ngBoneList.SetStretchFactor(1)
 
-	state.ngMorphList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelNgMorphTip), logger)
+	state.ngMorphList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelNgMorphTip)+"\n"+trackStatsTip, logger)
 	state.ngMorphList.SetMinSize(listMinSize)
 	state.ngMorphList.SetStretchFactor(1)
 
+	state.mappedBoneList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelMappedBoneTip)+"\n"+trackStatsTip, logger)
+	state.mappedBoneList.SetMinSize(listMinSize)
+	state.mappedBoneList.SetStretchFactor(1)
+
+	state.mappedMorphList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelMappedMorphTip)+"\n"+trackStatsTip, logger)
+	state.mappedMorphList.SetMinSize(listMinSize)
+	state.mappedMorphList.SetStretchFactor(1)
+
+	state.auditList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelAuditTip)+"\n"+trackStatsTip, logger)
+	state.auditList.SetMinSize(listMinSize)
+	state.auditList.SetStretchFactor(1)
+
+	state.lipSyncList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelLipSyncTip)+"\n"+trackStatsTip, logger)
+	state.lipSyncList.SetMinSize(listMinSize)
+	state.lipSyncList.SetStretchFactor(1)
+
+	state.ikList = NewListBoxWidget(i18n.TranslateOrMark(translator, messages.LabelIkFrameTip), logger)
+	state.ikList.SetMinSize(listMinSize)
+	state.ikList.SetStretchFactor(1)
+
 	if mWidgets != nil {
 		mWidgets.Widgets = append(mWidgets.Widgets,
 			state.player,
@@ -97,10 +273,34 @@ func NewTabPages(mWidgets *controller.MWidgets, baseServices base.IBaseServices,
 			state.motionPicker,
 			state.saveModelButton,
 			state.saveSafeMotionButton,
+			state.previewSafeButton,
+			state.saveFitMotionButton,
+			state.saveRenamedButton,
+			state.saveMirrorButton,
+			state.exportPoseButton,
+			state.exportCsvButton,
+			state.loadCsvButton,
+			state.outputDirButton,
+			state.saveRangeButton,
+			state.rangeStartButton,
+			state.rangeEndButton,
+			state.saveRetimeButton,
+			state.saveReduceButton,
+			state.poseImportButton,
+			state.poseFolderButton,
+			state.exportReportButton,
 			state.okBoneList,
 			state.okMorphList,
 			state.ngBoneList,
 			state.ngMorphList,
+			state.mappedBoneList,
+			state.mappedMorphList,
+			state.loadAliasButton,
+			state.loadProfileButton,
+			state.loadWeightsButton,
+			state.auditList,
+			state.lipSyncList,
+			state.ikList,
 		)
 		mWidgets.SetOnLoaded(func() {
 			if mWidgets == nil || mWidgets.Window() == nil {
@@ -131,6 +331,10 @@ func NewTabPages(mWidgets *controller.MWidgets, baseServices base.IBaseServices,
 				},
 			},
 			declarative.VSeparator{},
+			declarative.TextLabel{
+				AssignTo:    &state.scoreLabel,
+				ToolTipText: i18n.TranslateOrMark(translator, messages.LabelScoreTip),
+			},
 			declarative.Composite{
 				Layout: declarative.Grid{
 					Columns: 2,
@@ -156,6 +360,31 @@ func NewTabPages(mWidgets *controller.MWidgets, baseServices base.IBaseServices,
 						i18n.TranslateOrMark(translator, messages.LabelNgMorphTip),
 						state.ngMorphList,
 					),
+					buildListBoxColumn(
+						i18n.TranslateOrMark(translator, messages.LabelMappedBone),
+						i18n.TranslateOrMark(translator, messages.LabelMappedBoneTip),
+						state.mappedBoneList,
+					),
+					buildListBoxColumn(
+						i18n.TranslateOrMark(translator, messages.LabelMappedMorph),
+						i18n.TranslateOrMark(translator, messages.LabelMappedMorphTip),
+						state.mappedMorphList,
+					),
+					buildListBoxColumn(
+						i18n.TranslateOrMark(translator, messages.LabelAudit),
+						i18n.TranslateOrMark(translator, messages.LabelAuditTip),
+						state.auditList,
+					),
+					buildListBoxColumn(
+						i18n.TranslateOrMark(translator, messages.LabelLipSync),
+						i18n.TranslateOrMark(translator, messages.LabelLipSyncTip),
+						state.lipSyncList,
+					),
+					buildListBoxColumn(
+						i18n.TranslateOrMark(translator, messages.LabelIkFrame),
+						i18n.TranslateOrMark(translator, messages.LabelIkFrameTip),
+						state.ikList,
+					),
 				},
 			},
 			declarative.VSeparator{},
@@ -164,6 +393,257 @@ func NewTabPages(mWidgets *controller.MWidgets, baseServices base.IBaseServices,
 				Children: []declarative.Widget{
 					state.saveModelButton.Widgets(),
 					state.saveSafeMotionButton.Widgets(),
+					state.previewSafeButton.Widgets(),
+					state.saveFitMotionButton.Widgets(),
+					state.saveMirrorButton.Widgets(),
+					state.exportPoseButton.Widgets(),
+					state.exportCsvButton.Widgets(),
+					state.loadCsvButton.Widgets(),
+					state.exportReportButton.Widgets(),
+					state.loadAliasButton.Widgets(),
+					state.loadProfileButton.Widgets(),
+					state.loadWeightsButton.Widgets(),
+					declarative.CheckBox{
+						AssignTo:    &state.matchEnglishCheck,
+						Text:        i18n.TranslateOrMark(translator, messages.LabelMatchEnglish),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelMatchEnglishTip),
+						OnCheckedChanged: func() {
+							state.updateCheckLists()
+						},
+					},
+				},
+			},
+			declarative.Composite{
+				Layout:   declarative.HBox{},
+				Children: buildStripWidgets(translator, state),
+			},
+			declarative.Composite{
+				Layout: declarative.HBox{},
+				Children: []declarative.Widget{
+					state.saveRenamedButton.Widgets(),
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelRenamePolicy),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelRenamePolicyTip),
+					},
+					declarative.ComboBox{
+						AssignTo:     &state.renamePolicyCombo,
+						Model:        renamePolicyLabels,
+						CurrentIndex: 1,
+						ToolTipText:  i18n.TranslateOrMark(translator, messages.LabelRenamePolicyTip),
+					},
+					declarative.CheckBox{
+						AssignTo:    &state.renameSuggestCheck,
+						Text:        i18n.TranslateOrMark(translator, messages.LabelRenameSuggest),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelRenameSuggestTip),
+					},
+					declarative.HSpacer{},
+				},
+			},
+			declarative.Composite{
+				Layout: declarative.HBox{},
+				Children: []declarative.Widget{
+					state.saveRangeButton.Widgets(),
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelRangeStart),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelRangeMotionSaveTip),
+					},
+					declarative.NumberEdit{
+						AssignTo: &state.rangeStartEdit,
+						Decimals: 0,
+						MinValue: 0,
+						MaxValue: rangeFrameLimit,
+						MinSize:  declarative.Size{Width: 70},
+					},
+					state.rangeStartButton.Widgets(),
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelRangeEnd),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelRangeMotionSaveTip),
+					},
+					declarative.NumberEdit{
+						AssignTo: &state.rangeEndEdit,
+						Decimals: 0,
+						MinValue: 0,
+						MaxValue: rangeFrameLimit,
+						MinSize:  declarative.Size{Width: 70},
+					},
+					state.rangeEndButton.Widgets(),
+					declarative.HSpacer{},
+				},
+			},
+			declarative.Composite{
+				Layout: declarative.HBox{},
+				Children: []declarative.Widget{
+					state.saveRetimeButton.Widgets(),
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelRetimeMode),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelRetimeMotionSaveTip),
+					},
+					declarative.ComboBox{
+						AssignTo:     &state.retimeModeCombo,
+						Model:        retimeModeLabels,
+						CurrentIndex: 0,
+						ToolTipText:  i18n.TranslateOrMark(translator, messages.LabelRetimeMotionSaveTip),
+					},
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelRetimeFactor),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelRetimeFactorTip),
+					},
+					declarative.NumberEdit{
+						AssignTo:    &state.retimeFactorEdit,
+						Decimals:    2,
+						MinValue:    0.01,
+						MaxValue:    100,
+						Value:       1.0,
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelRetimeFactorTip),
+						MinSize:     declarative.Size{Width: 60},
+					},
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelRetimeInterval),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelRetimeIntervalTip),
+					},
+					declarative.NumberEdit{
+						AssignTo:    &state.retimeIntervalEdit,
+						Decimals:    0,
+						MinValue:    1,
+						MaxValue:    rangeFrameLimit,
+						Value:       2.0,
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelRetimeIntervalTip),
+						MinSize:     declarative.Size{Width: 60},
+					},
+					declarative.CheckBox{
+						AssignTo:    &state.retimeLossyCheck,
+						Text:        i18n.TranslateOrMark(translator, messages.LabelRetimeLossy),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelRetimeLossyTip),
+					},
+					declarative.HSpacer{},
+				},
+			},
+			declarative.Composite{
+				Layout: declarative.HBox{},
+				Children: []declarative.Widget{
+					state.saveReduceButton.Widgets(),
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelReduceAngle),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelReduceToleranceTip),
+					},
+					declarative.NumberEdit{
+						AssignTo:    &state.reduceAngleEdit,
+						Decimals:    2,
+						MinValue:    0,
+						MaxValue:    180,
+						Value:       reduceDefaults.AngleTolerance,
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelReduceToleranceTip),
+						MinSize:     declarative.Size{Width: 60},
+					},
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelReducePosition),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelReduceToleranceTip),
+					},
+					declarative.NumberEdit{
+						AssignTo:    &state.reducePositionEdit,
+						Decimals:    3,
+						MinValue:    0,
+						MaxValue:    100,
+						Value:       reduceDefaults.PositionTolerance,
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelReduceToleranceTip),
+						MinSize:     declarative.Size{Width: 60},
+					},
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelReduceMorph),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelReduceToleranceTip),
+					},
+					declarative.NumberEdit{
+						AssignTo:    &state.reduceMorphEdit,
+						Decimals:    3,
+						MinValue:    0,
+						MaxValue:    1,
+						Value:       reduceDefaults.MorphTolerance,
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelReduceToleranceTip),
+						MinSize:     declarative.Size{Width: 60},
+					},
+					declarative.CheckBox{
+						AssignTo:    &state.reduceFitCheck,
+						Text:        i18n.TranslateOrMark(translator, messages.LabelReduceFitCurves),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelReduceFitCurvesTip),
+						Checked:     reduceDefaults.FitCurves,
+					},
+					declarative.HSpacer{},
+				},
+			},
+			declarative.Composite{
+				Layout: declarative.HBox{},
+				Children: []declarative.Widget{
+					state.poseImportButton.Widgets(),
+					state.poseFolderButton.Widgets(),
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelPoseSpacing),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelPoseSpacingTip),
+					},
+					declarative.NumberEdit{
+						AssignTo:    &state.poseSpacingEdit,
+						Decimals:    0,
+						MinValue:    1,
+						MaxValue:    rangeFrameLimit,
+						Value:       float64(poseDefaults.Spacing),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelPoseSpacingTip),
+						MinSize:     declarative.Size{Width: 60},
+					},
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelPosePreset),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelPosePresetTip),
+					},
+					declarative.ComboBox{
+						AssignTo:     &state.posePresetCombo,
+						Model:        posePresetLabels,
+						CurrentIndex: 0,
+						ToolTipText:  i18n.TranslateOrMark(translator, messages.LabelPosePresetTip),
+					},
+					declarative.HSpacer{},
+				},
+			},
+			declarative.Composite{
+				Layout: declarative.HBox{},
+				Children: []declarative.Widget{
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelOutputTemplate),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelOutputTemplateTip),
+					},
+					declarative.LineEdit{
+						AssignTo:    &state.outputTemplateEdit,
+						Text:        minteractor.DefaultOutputTemplate,
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelOutputTemplateTip),
+						MinSize:     declarative.Size{Width: 140},
+						OnEditingFinished: func() {
+							state.saveOutputSettings()
+						},
+					},
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelOutputDir),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelOutputDirTip),
+					},
+					declarative.LineEdit{
+						AssignTo:    &state.outputDirEdit,
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelOutputDirTip),
+						MinSize:     declarative.Size{Width: 200},
+						OnEditingFinished: func() {
+							state.saveOutputSettings()
+						},
+					},
+					state.outputDirButton.Widgets(),
+					declarative.TextLabel{
+						Text:        i18n.TranslateOrMark(translator, messages.LabelOutputCollision),
+						ToolTipText: i18n.TranslateOrMark(translator, messages.LabelOutputCollisionTip),
+					},
+					declarative.ComboBox{
+						AssignTo:     &state.outputCollisionCombo,
+						Model:        collisionLabels,
+						CurrentIndex: 0,
+						ToolTipText:  i18n.TranslateOrMark(translator, messages.LabelOutputCollisionTip),
+						OnCurrentIndexChanged: func() {
+							state.saveOutputSettings()
+						},
+					},
+					declarative.HSpacer{},
 				},
 			},
 			declarative.VSeparator{},
@@ -197,3 +677,98 @@ func buildListBoxColumn(label string, tooltip string, listBox *ListBoxWidget) de
 		},
 	}
 }
+
+// renamePolicyLabelKeys は重複時の扱いごとの表示名のメッセージキー。
+var renamePolicyLabelKeys = map[minteractor.RenameConflictPolicy]string{
+	minteractor.RenameOverwrite: messages.LabelRenameOverwrite,
+	minteractor.RenameSkip:      messages.LabelRenameSkip,
+	minteractor.RenameMerge:     messages.LabelRenameMerge,
+}
+
+// rangeFrameLimit は区間書き出しで指定できるフレームの上限。
+const rangeFrameLimit = 999999
+
+// retimeModeLabelKeys は時間変換の種類ごとの表示名のメッセージキー。
+var retimeModeLabelKeys = map[minteractor.RetimeMode]string{
+	minteractor.RetimeStretch:  messages.LabelRetimeStretch,
+	minteractor.RetimeResample: messages.LabelRetimeResample,
+}
+
+// interpolationPresetLabelKeys は補間曲線の種類ごとの表示名のメッセージキー。
+var interpolationPresetLabelKeys = map[minteractor.InterpolationPreset]string{
+	minteractor.InterpolationLinear:    messages.LabelPresetLinear,
+	minteractor.InterpolationEaseInOut: messages.LabelPresetEaseInOut,
+	minteractor.InterpolationEaseIn:    messages.LabelPresetEaseIn,
+	minteractor.InterpolationEaseOut:   messages.LabelPresetEaseOut,
+}
+
+// collisionPolicyLabelKeys は保存先の重複時の扱いごとの表示名のメッセージキー。
+var collisionPolicyLabelKeys = map[minteractor.CollisionPolicy]string{
+	minteractor.CollisionOverwrite: messages.LabelCollisionOverwrite,
+	minteractor.CollisionIncrement: messages.LabelCollisionIncrement,
+	minteractor.CollisionSkip:      messages.LabelCollisionSkip,
+	minteractor.CollisionAsk:       messages.LabelCollisionAsk,
+}
+
+// diffSectionLabelKeys はモーション差分の区分ごとの表示名のメッセージキー。
+var diffSectionLabelKeys = map[minteractor.DiffSection]string{
+	minteractor.DiffSectionBone:   messages.LabelDiffBone,
+	minteractor.DiffSectionMorph:  messages.LabelDiffMorph,
+	minteractor.DiffSectionIk:     messages.LabelStripIk,
+	minteractor.DiffSectionCamera: messages.LabelStripCamera,
+	minteractor.DiffSectionLight:  messages.LabelStripLight,
+	minteractor.DiffSectionShadow: messages.LabelStripShadow,
+}
+
+// stripKindLabels は除去操作ごとのラベルと説明のメッセージキー。
+var stripKindLabels = map[minteractor.StripKind][2]string{
+	minteractor.StripBakeIk:       {messages.LabelStripBakeIk, messages.LabelStripBakeIkTip},
+	minteractor.StripIkFrames:     {messages.LabelStripIk, messages.LabelStripIkTip},
+	minteractor.StripCameraFrames: {messages.LabelStripCamera, messages.LabelStripCameraTip},
+	minteractor.StripLightFrames:  {messages.LabelStripLight, messages.LabelStripLightTip},
+	minteractor.StripShadowFrames: {messages.LabelStripShadow, messages.LabelStripShadowTip},
+	minteractor.StripAllMorphs:    {messages.LabelStripMorphs, messages.LabelStripMorphsTip},
+	minteractor.StripAllBones:     {messages.LabelStripBones, messages.LabelStripBonesTip},
+	minteractor.StripNames:        {messages.LabelStripNames, messages.LabelStripNamesTip},
+	minteractor.StripPattern:      {messages.LabelStripPattern, messages.LabelStripPatternTip},
+	minteractor.StripNgTracks:     {messages.LabelStripNg, messages.LabelStripNgTip},
+}
+
+// buildStripWidgets は安全モーション保存の除去操作を選ぶチェックボックスと入力欄を構成する。
+func buildStripWidgets(translator i18n.II18n, state *motionViewerState) []declarative.Widget {
+	widgets := []declarative.Widget{
+		declarative.TextLabel{
+			Text:        i18n.TranslateOrMark(translator, messages.LabelStrip),
+			ToolTipText: i18n.TranslateOrMark(translator, messages.LabelStripTip),
+		},
+	}
+	for i, kind := range minteractor.StripKinds {
+		labels := stripKindLabels[kind]
+		widgets = append(widgets, declarative.CheckBox{
+			AssignTo:    &state.stripChecks[i],
+			Text:        i18n.TranslateOrMark(translator, labels[0]),
+			ToolTipText: i18n.TranslateOrMark(translator, labels[1]),
+		})
+		switch kind {
+		case minteractor.StripBakeIk:
+			widgets = append(widgets, declarative.CheckBox{
+				AssignTo:    &state.bakeEveryCheck,
+				Text:        i18n.TranslateOrMark(translator, messages.LabelStripBakeEvery),
+				ToolTipText: i18n.TranslateOrMark(translator, messages.LabelStripBakeEveryTip),
+			})
+		case minteractor.StripNames:
+			widgets = append(widgets, declarative.LineEdit{
+				AssignTo:    &state.stripNamesEdit,
+				ToolTipText: i18n.TranslateOrMark(translator, labels[1]),
+				MinSize:     declarative.Size{Width: 120},
+			})
+		case minteractor.StripPattern:
+			widgets = append(widgets, declarative.LineEdit{
+				AssignTo:    &state.stripPatternEdit,
+				ToolTipText: i18n.TranslateOrMark(translator, labels[1]),
+				MinSize:     declarative.Size{Width: 120},
+			})
+		}
+	}
+	return widgets
+}
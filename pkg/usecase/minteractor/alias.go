@@ -0,0 +1,129 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AliasDictionary はモーション側の名前からモデル側の名前への別名辞書を表す。
+type AliasDictionary struct {
+	Bones  map[string]string `json:"bones"`
+	Morphs map[string]string `json:"morphs"`
+}
+
+// NewAliasDictionary は空の別名辞書を生成する。
+func NewAliasDictionary() *AliasDictionary {
+	return &AliasDictionary{
+		Bones:  map[string]string{},
+		Morphs: map[string]string{},
+	}
+}
+
+// LoadAliasDictionary は拡張子に応じてJSONまたはCSVの別名辞書を読み込む。
+func LoadAliasDictionary(path string) (*AliasDictionary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// BOM付きで保存された辞書も読めるようにする。
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return parseAliasJSON(data)
+	case ".csv":
+		return parseAliasCSV(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("未対応の別名辞書形式です: %s", path)
+	}
+}
+
+// Merge は別の辞書の内容を上書きで取り込む。
+func (d *AliasDictionary) Merge(other *AliasDictionary) {
+	if d == nil || other == nil {
+		return
+	}
+	if d.Bones == nil {
+		d.Bones = map[string]string{}
+	}
+	if d.Morphs == nil {
+		d.Morphs = map[string]string{}
+	}
+	for name, target := range other.Bones {
+		d.Bones[name] = target
+	}
+	for name, target := range other.Morphs {
+		d.Morphs[name] = target
+	}
+}
+
+// BoneTarget はボーン名の対応先を返す。
+func (d *AliasDictionary) BoneTarget(name string) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	target, ok := d.Bones[name]
+	return target, ok && target != ""
+}
+
+// MorphTarget はモーフ名の対応先を返す。
+func (d *AliasDictionary) MorphTarget(name string) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	target, ok := d.Morphs[name]
+	return target, ok && target != ""
+}
+
+// parseAliasJSON はJSON形式の別名辞書を解析する。
+func parseAliasJSON(data []byte) (*AliasDictionary, error) {
+	dict := NewAliasDictionary()
+	if err := json.Unmarshal(data, dict); err != nil {
+		return nil, err
+	}
+	if dict.Bones == nil {
+		dict.Bones = map[string]string{}
+	}
+	if dict.Morphs == nil {
+		dict.Morphs = map[string]string{}
+	}
+	return dict, nil
+}
+
+// parseAliasCSV は「種別,モーション側,モデル側」形式の別名辞書を解析する。
+func parseAliasCSV(r io.Reader) (*AliasDictionary, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	dict := NewAliasDictionary()
+	for i, record := range records {
+		if len(record) < 3 {
+			continue
+		}
+		name := strings.TrimSpace(record[1])
+		target := strings.TrimSpace(record[2])
+		switch strings.ToLower(strings.TrimSpace(record[0])) {
+		case "bone", "ボーン":
+			dict.Bones[name] = target
+		case "morph", "モーフ":
+			dict.Morphs[name] = target
+		default:
+			if i == 0 {
+				// 先頭行はヘッダとして読み飛ばす。
+				continue
+			}
+			return nil, fmt.Errorf("別名辞書の種別が不正です: %d行目 %s", i+1, record[0])
+		}
+	}
+	return dict, nil
+}
@@ -0,0 +1,182 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/model"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mlib_go/pkg/usecase"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// CompatibilityMatrixRequest は複数モデル×複数モーションの一括判定の入力を表す。
+type CompatibilityMatrixRequest struct {
+	// ModelPaths/MotionPaths にはファイルまたはフォルダを指定する。
+	ModelPaths  []string
+	MotionPaths []string
+	Options     CheckOptions
+	// Weights は互換スコアの重み付け。nilの場合は既定の重み付けを使う。
+	Weights *ScoreWeights
+}
+
+// CompatibilityCell はモデルとモーション1組の判定件数を表す。
+// OK/NGは互換スコアと同じ分類で数え、切り詰め一致はOK、対応付けのみ・曖昧な一致はNGに含める。
+type CompatibilityCell struct {
+	OkBones  int
+	OkMorphs int
+	NgBones  int
+	NgMorphs int
+	// Score は指定の重み付けによる互換スコア。
+	Score float64
+	// Err はモデル/モーションの読み込みまたは判定の失敗内容。
+	Err error
+}
+
+// CompatibilityMatrix は一括判定の結果を表す。
+type CompatibilityMatrix struct {
+	ModelPaths  []string
+	MotionPaths []string
+	// Cells は [モデル][モーション] の順に並ぶ。
+	Cells [][]CompatibilityCell
+}
+
+// OkCount はOKのボーン/モーフ数を返す。
+func (c CompatibilityCell) OkCount() int {
+	return c.OkBones + c.OkMorphs
+}
+
+// NgCount はNGのボーン/モーフ数を返す。
+func (c CompatibilityCell) NgCount() int {
+	return c.NgBones + c.NgMorphs
+}
+
+// OkPercent はOKの割合を百分率で返す。判定対象がない場合は100を返す。
+func (c CompatibilityCell) OkPercent() float64 {
+	total := c.OkCount() + c.NgCount()
+	if total == 0 {
+		return 100
+	}
+	return float64(c.OkCount()) * 100 / float64(total)
+}
+
+// CheckMatrix は全てのモデルとモーションの組についてOK/NGを判定する。
+// 各ファイルは1回だけ読み込み、モーションは保持したままモデルを順に読み込む。
+func (uc *MotionViewerUsecase) CheckMatrix(request CompatibilityMatrixRequest) (*CompatibilityMatrix, error) {
+	modelPaths, err := expandLoadablePaths(uc.modelReader, request.ModelPaths)
+	if err != nil {
+		return nil, err
+	}
+	motionPaths, err := expandLoadablePaths(uc.motionReader, request.MotionPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := DefaultScoreWeights()
+	if request.Weights != nil {
+		weights = *request.Weights
+	}
+
+	motions := make([]*motion.VmdMotion, len(motionPaths))
+	motionErrors := make([]error, len(motionPaths))
+	for i, path := range motionPaths {
+		motionData, err := usecase.LoadMotion(uc.motionReader, path)
+		if err != nil {
+			motionErrors[i] = err
+			continue
+		}
+		motions[i] = motionData
+	}
+
+	matrix := &CompatibilityMatrix{
+		ModelPaths:  modelPaths,
+		MotionPaths: motionPaths,
+		Cells:       make([][]CompatibilityCell, len(modelPaths)),
+	}
+	for i, modelPath := range modelPaths {
+		matrix.Cells[i] = make([]CompatibilityCell, len(motionPaths))
+		modelData, modelErr := usecase.LoadModel(uc.modelReader, modelPath)
+		for j := range motionPaths {
+			switch {
+			case modelErr != nil:
+				matrix.Cells[i][j].Err = modelErr
+			case motionErrors[j] != nil:
+				matrix.Cells[i][j].Err = motionErrors[j]
+			default:
+				matrix.Cells[i][j] = checkCell(modelData, motions[j], request.Options, weights)
+			}
+		}
+	}
+	return matrix, nil
+}
+
+// checkCell は1組のモデルとモーションを判定して件数を集計する。
+func checkCell(modelData *model.PmxModel, motionData *motion.VmdMotion, options CheckOptions, weights ScoreWeights) CompatibilityCell {
+	result, err := CheckExistsWithOptions(modelData, motionData, options)
+	if err != nil {
+		return CompatibilityCell{Err: err}
+	}
+	cell := CompatibilityCell{Score: ScoreCompatibility(result, weights).Score}
+	forEachScoredTrack(result, func(_ TrackEntry, kind string, ok bool) {
+		switch {
+		case kind == ScoreKindBone && ok:
+			cell.OkBones++
+		case kind == ScoreKindBone:
+			cell.NgBones++
+		case ok:
+			cell.OkMorphs++
+		default:
+			cell.NgMorphs++
+		}
+	})
+	return cell
+}
+
+// expandLoadablePaths はフォルダを読み込み可能なファイルへ展開し、重複を除いて返す。
+func expandLoadablePaths(rep moutput.IFileReader, paths []string) ([]string, error) {
+	if rep == nil {
+		return nil, fmt.Errorf("読み込みリポジトリがありません")
+	}
+	out := make([]string, 0, len(paths))
+	seen := make(map[string]struct{})
+	add := func(path string) {
+		if _, ok := seen[path]; ok {
+			return
+		}
+		seen[path] = struct{}{}
+		out = append(out, path)
+	}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			add(path)
+			continue
+		}
+		found := make([]string, 0)
+		err = filepath.WalkDir(path, func(child string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || !usecase.CanLoadPath(rep, child) {
+				return nil
+			}
+			found = append(found, child)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(found)
+		for _, child := range found {
+			add(child)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,258 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"math"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+)
+
+// curveScale はVMDの補間曲線の制御点の最大値。
+const curveScale = 127.0
+
+// refineSampleCount は補間曲線の当てはめで、格子点の探索に使う点の数の目安。
+const refineSampleCount = 24
+
+// bezierPoint は正規化した補間曲線の制御点を表す。
+type bezierPoint struct {
+	X, Y float64
+}
+
+// lerp は2点の間を t で補間する。
+func (p bezierPoint) lerp(q bezierPoint, t float64) bezierPoint {
+	return bezierPoint{X: p.X + (q.X-p.X)*t, Y: p.Y + (q.Y-p.Y)*t}
+}
+
+// bezierCurve は (0,0) から (1,1) への3次ベジェ曲線を表す。
+type bezierCurve [4]bezierPoint
+
+// newBezierCurve はVMDの補間曲線を正規化した制御点にする。nil は線形補間とする。
+func newBezierCurve(curve *mmath.Curve) bezierCurve {
+	if curve == nil {
+		return bezierCurve{{0, 0}, {0, 0}, {1, 1}, {1, 1}}
+	}
+	return bezierCurve{
+		{0, 0},
+		{curve.Start.X / curveScale, curve.Start.Y / curveScale},
+		{curve.End.X / curveScale, curve.End.Y / curveScale},
+		{1, 1},
+	}
+}
+
+// split はパラメータ t で曲線を2つに分割する（de Casteljau法）。
+func (c bezierCurve) split(t float64) (bezierCurve, bezierCurve) {
+	p01 := c[0].lerp(c[1], t)
+	p12 := c[1].lerp(c[2], t)
+	p23 := c[2].lerp(c[3], t)
+	p012 := p01.lerp(p12, t)
+	p123 := p12.lerp(p23, t)
+	mid := p012.lerp(p123, t)
+	return bezierCurve{c[0], p01, p012, mid}, bezierCurve{mid, p123, p23, c[3]}
+}
+
+// paramAt はX座標(フレームの進み具合)が x となるパラメータを求める。
+// 制御点のX座標が [0,1] に収まる曲線ではX座標は単調増加するため、ニュートン法を二分法で補って解く。
+func (c bezierCurve) paramAt(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	low, high := 0.0, 1.0
+	t := x
+	for i := 0; i < 64; i++ {
+		diff := bezierAxis(c[1].X, c[2].X, t) - x
+		if math.Abs(diff) < 1e-12 {
+			return t
+		}
+		if diff < 0 {
+			low = t
+		} else {
+			high = t
+		}
+		slope := bezierAxisSlope(c[1].X, c[2].X, t)
+		next := t - diff/slope
+		if slope < 1e-9 || next <= low || next >= high {
+			next = (low + high) / 2
+		}
+		t = next
+	}
+	return t
+}
+
+// valueAt はX座標(フレームの進み具合)が x のときのY座標(値の進み具合)を返す。
+func (c bezierCurve) valueAt(x float64) float64 {
+	return bezierAxis(c[1].Y, c[2].Y, c.paramAt(x))
+}
+
+// bezierAxis は始点0・終点1の3次ベジェ曲線の1軸について、制御点 p1, p2 のときのパラメータ t の値を返す。
+func bezierAxis(p1 float64, p2 float64, t float64) float64 {
+	s := 1 - t
+	return 3*s*s*t*p1 + 3*s*t*t*p2 + t*t*t
+}
+
+// bezierAxisSlope は bezierAxis の t による微分を返す。
+func bezierAxisSlope(p1 float64, p2 float64, t float64) float64 {
+	s := 1 - t
+	return 3*s*s*p1 + 6*s*t*(p2-p1) + 3*t*t*(1-p2)
+}
+
+// subCurve は補間曲線のうちX座標が from から to の区間を取り出し、VMDの補間曲線として返す。
+// 区間の値が変化しない場合や曲線が nil の場合は線形補間を返す。
+func subCurve(curve *mmath.Curve, from float64, to float64) *mmath.Curve {
+	if curve == nil {
+		return nil
+	}
+	if from <= 0 && to >= 1 {
+		return curve.Copy()
+	}
+	if to-from < 1e-9 {
+		return mmath.NewCurve()
+	}
+	bezier := newBezierCurve(curve)
+	t1 := bezier.paramAt(from)
+	t2 := bezier.paramAt(to)
+	left, _ := bezier.split(t2)
+	part := left
+	if t2 > 0 {
+		_, part = left.split(t1 / t2)
+	}
+	return normalizeBezierCurve(part)
+}
+
+// normalizeBezierCurve は任意の区間のベジェ曲線を (0,0)-(1,1) に正規化し、VMDの補間曲線に丸める。
+func normalizeBezierCurve(part bezierCurve) *mmath.Curve {
+	dx := part[3].X - part[0].X
+	dy := part[3].Y - part[0].Y
+	if math.Abs(dx) < 1e-9 || math.Abs(dy) < 1e-9 {
+		return mmath.NewCurve()
+	}
+	scale := func(value float64, origin float64, size float64) float64 {
+		normalized := (value - origin) / size
+		return math.Round(math.Max(0, math.Min(1, normalized)) * curveScale)
+	}
+	curve := mmath.NewCurve()
+	curve.Start.X = scale(part[1].X, part[0].X, dx)
+	curve.Start.Y = scale(part[1].Y, part[0].Y, dy)
+	curve.End.X = scale(part[2].X, part[0].X, dx)
+	curve.End.Y = scale(part[2].Y, part[0].Y, dy)
+	return curve
+}
+
+// fitBezierCurve は (0,0)-(1,1) を結ぶ点列 (xs, ys) に最小二乗法で補間曲線を当てはめ、VMDの補間曲線に丸める。
+// 点のパラメータはX座標で初期化し、当てはめた曲線で求め直して数回繰り返す。
+// 全ての点のY座標の誤差が tolerance 以内になった時点で探索を打ち切る。
+func fitBezierCurve(xs []float64, ys []float64, tolerance float64) *mmath.Curve {
+	curve := newBezierCurve(mmath.NewCurve())
+	if len(xs) == 0 || len(xs) != len(ys) {
+		return mmath.NewCurve()
+	}
+	params := append([]float64(nil), xs...)
+	for iteration := 0; iteration < 4; iteration++ {
+		x1, x2 := fitBezierAxis(params, xs, curve[1].X, curve[2].X)
+		y1, y2 := fitBezierAxis(params, ys, curve[1].Y, curve[2].Y)
+		curve = bezierCurve{{0, 0}, {x1, y1}, {x2, y2}, {1, 1}}
+		for i, x := range xs {
+			params[i] = curve.paramAt(x)
+		}
+	}
+	return refineBezierCurve(normalizeBezierCurve(curve), xs, ys, tolerance)
+}
+
+// refineBezierCurve は制御点をVMDの整数の範囲で動かし、点列との二乗誤差が小さくなる補間曲線を探す。
+// 最小二乗法の結果は丸めやパラメータの近似で最適からずれるため、刻みを半分にしながら周囲の格子点へ山登りで詰める。
+// 各値を1つずつずらして改善しなくなったら、全ての値を同時にずらす組み合わせも試す。
+func refineBezierCurve(curve *mmath.Curve, xs []float64, ys []float64, tolerance float64) *mmath.Curve {
+	values := [4]float64{curve.Start.X, curve.Start.Y, curve.End.X, curve.End.Y}
+	// 点が多い場合は間隔を空けて比べる。最終的な誤差は呼び出し側で全ての点を比べて確かめる。
+	if stride := len(xs) / refineSampleCount; stride > 1 {
+		sampledXs := make([]float64, 0, refineSampleCount+1)
+		sampledYs := make([]float64, 0, refineSampleCount+1)
+		for i := 0; i < len(xs); i += stride {
+			sampledXs = append(sampledXs, xs[i])
+			sampledYs = append(sampledYs, ys[i])
+		}
+		xs, ys = sampledXs, sampledYs
+	}
+	measure := func(values [4]float64) (float64, float64) {
+		candidate := bezierCurve{
+			{0, 0},
+			{values[0] / curveScale, values[1] / curveScale},
+			{values[2] / curveScale, values[3] / curveScale},
+			{1, 1},
+		}
+		sum, worst := 0.0, 0.0
+		for i, x := range xs {
+			diff := candidate.valueAt(x) - ys[i]
+			sum += diff * diff
+			worst = math.Max(worst, math.Abs(diff))
+		}
+		return sum, worst
+	}
+	best, worst := measure(values)
+	// try は制御点を moves だけずらした候補のほうが誤差が小さければ採用する。
+	try := func(moves [4]float64) bool {
+		candidate := values
+		for i, move := range moves {
+			candidate[i] = math.Max(0, math.Min(curveScale, candidate[i]+move))
+		}
+		sum, candidateWorst := measure(candidate)
+		if sum >= best-1e-12 {
+			return false
+		}
+		values, best, worst = candidate, sum, candidateWorst
+		return true
+	}
+	for step := 32.0; step >= 1 && worst > tolerance; step /= 2 {
+		for improved := true; improved && worst > tolerance; {
+			improved = false
+			for i := range values {
+				for _, sign := range []float64{-1, 1} {
+					moves := [4]float64{}
+					moves[i] = sign * step
+					improved = try(moves) || improved
+				}
+			}
+			if improved {
+				continue
+			}
+			for code := 0; code < 81; code++ {
+				moves := [4]float64{}
+				for i, rest := 0, code; i < len(moves); i, rest = i+1, rest/3 {
+					moves[i] = float64(rest%3-1) * step
+				}
+				improved = try(moves) || improved
+			}
+		}
+	}
+	refined := mmath.NewCurve()
+	refined.Start.X, refined.Start.Y = values[0], values[1]
+	refined.End.X, refined.End.Y = values[2], values[3]
+	return refined
+}
+
+// fitBezierAxis はパラメータ params での値が values に近くなる1軸の制御点を求め、[0,1] に収める。
+// 解が定まらない場合は p1, p2 をそのまま返す。
+func fitBezierAxis(params []float64, values []float64, p1 float64, p2 float64) (float64, float64) {
+	var a11, a12, a22, r1, r2 float64
+	for i, t := range params {
+		s := 1 - t
+		b1 := 3 * s * s * t
+		b2 := 3 * s * t * t
+		rest := values[i] - t*t*t
+		a11 += b1 * b1
+		a12 += b1 * b2
+		a22 += b2 * b2
+		r1 += b1 * rest
+		r2 += b2 * rest
+	}
+	det := a11*a22 - a12*a12
+	if math.Abs(det) < 1e-12 {
+		return p1, p2
+	}
+	clamp := func(value float64) float64 {
+		return math.Max(0, math.Min(1, value))
+	}
+	return clamp((r1*a22 - r2*a12) / det), clamp((a11*r2 - a12*r1) / det)
+}
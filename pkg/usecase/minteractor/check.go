@@ -3,6 +3,7 @@ package minteractor
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/miu200521358/mlib_go/pkg/domain/model"
 	"github.com/miu200521358/mlib_go/pkg/domain/model/merrors"
@@ -11,12 +12,27 @@ import (
 
 // indexedName は表示順の並び替えに使う一時構造体。
 type indexedName struct {
-	Name  string
+	Entry TrackEntry
 	Index int
 }
 
+// CheckOptions はOK/NG判定の追加解決手段を表す。
+type CheckOptions struct {
+	// Aliases は完全一致しなかった名前を解決する別名辞書。
+	Aliases *AliasDictionary
+	// MatchEnglishName はモデルの英名との照合も行うかを表す。
+	MatchEnglishName bool
+	// Profiles は不足ボーン監査に使うボーンプロファイル。
+	Profiles []BoneProfile
+}
+
 // CheckExists はモーション内のボーン/モーフがモデルに存在するか判定する。
 func CheckExists(modelData *model.PmxModel, motionData *motion.VmdMotion) (CheckResult, error) {
+	return CheckExistsWithOptions(modelData, motionData, CheckOptions{Profiles: BuiltinBoneProfiles()})
+}
+
+// CheckExistsWithOptions は追加の解決手段を使ってOK/NGを判定する。
+func CheckExistsWithOptions(modelData *model.PmxModel, motionData *motion.VmdMotion, options CheckOptions) (CheckResult, error) {
 	result := CheckResult{}
 	if motionData == nil {
 		return result, nil
@@ -24,38 +40,134 @@ func CheckExists(modelData *model.PmxModel, motionData *motion.VmdMotion) (Check
 
 	activeBoneNames := collectActiveBoneNames(motionData)
 	okBoneEntries := make([]indexedName, 0, len(activeBoneNames))
-	ngBoneNames := make([]string, 0, len(activeBoneNames))
+	ngBoneEntries := make([]TrackEntry, 0, len(activeBoneNames))
+	mappedBones := make([]MappedName, 0)
+	englishBones := make([]MappedName, 0)
+	truncatedBones := make([]MappedName, 0)
+	ambiguousBones := make([]AmbiguousName, 0)
+	boneTruncationIndex := buildBoneTruncationIndex(modelData)
+	englishBoneIndex := map[string]string(nil)
+	if options.MatchEnglishName {
+		englishBoneIndex = buildEnglishBoneIndex(modelData)
+	}
 	for _, name := range activeBoneNames {
+		entry := TrackEntry{Name: name, TrackStats: collectBoneStats(motionData.BoneFrames.Get(name))}
 		bone, ok, err := resolveBone(modelData, name)
 		if err != nil {
 			return CheckResult{}, err
 		}
 		if ok && bone != nil && name == bone.Name() {
-			okBoneEntries = append(okBoneEntries, indexedName{Name: name, Index: bone.Index()})
+			okBoneEntries = append(okBoneEntries, indexedName{Entry: entry, Index: bone.Index()})
+			continue
+		}
+		if matches := boneTruncationIndex.match(name); len(matches) == 1 {
+			truncatedBones = append(truncatedBones, MappedName{TrackEntry: entry, Target: matches[0]})
+			continue
+		} else if len(matches) > 1 {
+			ambiguousBones = append(ambiguousBones, AmbiguousName{TrackEntry: entry, Candidates: matches})
+			continue
+		}
+		if target, ok := options.Aliases.BoneTarget(name); ok {
+			_, exists, err := resolveBone(modelData, target)
+			if err != nil {
+				return CheckResult{}, err
+			}
+			if exists {
+				mappedBones = append(mappedBones, MappedName{TrackEntry: entry, Target: target})
+				continue
+			}
+		}
+		if target, ok := englishBoneIndex[normalizeEnglishName(name)]; ok {
+			englishBones = append(englishBones, MappedName{TrackEntry: entry, Target: target})
 			continue
 		}
-		ngBoneNames = append(ngBoneNames, name)
+		ngBoneEntries = append(ngBoneEntries, entry)
 	}
 
 	activeMorphNames := collectActiveMorphNames(motionData)
 	okMorphEntries := make([]indexedName, 0, len(activeMorphNames))
-	ngMorphNames := make([]string, 0, len(activeMorphNames))
+	ngMorphEntries := make([]TrackEntry, 0, len(activeMorphNames))
+	mappedMorphs := make([]MappedName, 0)
+	englishMorphs := make([]MappedName, 0)
+	truncatedMorphs := make([]MappedName, 0)
+	ambiguousMorphs := make([]AmbiguousName, 0)
+	morphTruncationIndex := buildMorphTruncationIndex(modelData)
+	englishMorphIndex := map[string]string(nil)
+	if options.MatchEnglishName {
+		englishMorphIndex = buildEnglishMorphIndex(modelData)
+	}
 	for _, name := range activeMorphNames {
+		entry := TrackEntry{Name: name, TrackStats: collectMorphStats(motionData.MorphFrames.Get(name))}
 		morph, ok, err := resolveMorph(modelData, name)
 		if err != nil {
 			return CheckResult{}, err
 		}
 		if ok && morph != nil && name == morph.Name() {
-			okMorphEntries = append(okMorphEntries, indexedName{Name: name, Index: morph.Index()})
+			okMorphEntries = append(okMorphEntries, indexedName{Entry: entry, Index: morph.Index()})
+			continue
+		}
+		if matches := morphTruncationIndex.match(name); len(matches) == 1 {
+			truncatedMorphs = append(truncatedMorphs, MappedName{TrackEntry: entry, Target: matches[0]})
+			continue
+		} else if len(matches) > 1 {
+			ambiguousMorphs = append(ambiguousMorphs, AmbiguousName{TrackEntry: entry, Candidates: matches})
+			continue
+		}
+		if target, ok := options.Aliases.MorphTarget(name); ok {
+			_, exists, err := resolveMorph(modelData, target)
+			if err != nil {
+				return CheckResult{}, err
+			}
+			if exists {
+				mappedMorphs = append(mappedMorphs, MappedName{TrackEntry: entry, Target: target})
+				continue
+			}
+		}
+		if target, ok := englishMorphIndex[normalizeEnglishName(name)]; ok {
+			englishMorphs = append(englishMorphs, MappedName{TrackEntry: entry, Target: target})
 			continue
 		}
-		ngMorphNames = append(ngMorphNames, name)
+		ngMorphEntries = append(ngMorphEntries, entry)
 	}
 
 	result.OkBones = sortNamesByIndex(okBoneEntries)
 	result.OkMorphs = sortNamesByIndex(okMorphEntries)
-	result.NgBones = sortNamesByName(ngBoneNames)
-	result.NgMorphs = sortNamesByName(ngMorphNames)
+	result.NgBones = sortEntriesByKeyCount(ngBoneEntries)
+	result.NgMorphs = sortEntriesByKeyCount(ngMorphEntries)
+	result.MappedBones = sortMappedNames(mappedBones)
+	result.MappedMorphs = sortMappedNames(mappedMorphs)
+	result.EnglishBones = sortMappedNames(englishBones)
+	result.EnglishMorphs = sortMappedNames(englishMorphs)
+	result.TruncatedBones = sortMappedNames(truncatedBones)
+	result.TruncatedMorphs = sortMappedNames(truncatedMorphs)
+	result.AmbiguousBones = sortAmbiguousNames(ambiguousBones)
+	result.AmbiguousMorphs = sortAmbiguousNames(ambiguousMorphs)
+	result.NgBoneSuggestions = SuggestBoneNames(modelData, TrackNames(result.NgBones))
+	result.NgMorphSuggestions = SuggestMorphNames(modelData, TrackNames(result.NgMorphs))
+
+	profileAudit, err := AuditBoneProfiles(modelData, motionData, options.Profiles)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	result.ProfileAudit = profileAudit
+
+	okMorphGroups, err := GroupMorphsByPanel(modelData, result.OkMorphs)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	result.OkMorphGroups = okMorphGroups
+	ngMorphGroups, err := GroupMorphsByPanel(modelData, result.NgMorphs)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	result.NgMorphGroups = ngMorphGroups
+	result.LipSyncAudit = AuditLipSync(result.NgMorphs)
+
+	ikBones, err := CheckIkFrames(modelData, motionData)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	result.IkBones = ikBones
 	return result, nil
 }
 
@@ -125,8 +237,58 @@ func resolveMorph(modelData *model.PmxModel, name string) (*model.Morph, bool, e
 	return morph, true, nil
 }
 
-// sortNamesByIndex はインデックス順に名前を並べ替える。
-func sortNamesByIndex(entries []indexedName) []string {
+// buildEnglishBoneIndex は正規化した英名からボーン名への索引を作る。
+func buildEnglishBoneIndex(modelData *model.PmxModel) map[string]string {
+	if modelData == nil || modelData.Bones == nil {
+		return nil
+	}
+	index := make(map[string]string, modelData.Bones.Len())
+	for _, bone := range modelData.Bones.Values() {
+		if bone == nil {
+			continue
+		}
+		key := normalizeEnglishName(bone.EnglishName)
+		if key == "" {
+			continue
+		}
+		if _, exists := index[key]; exists {
+			// 英名の重複時はインデックスの若いボーンを優先する。
+			continue
+		}
+		index[key] = bone.Name()
+	}
+	return index
+}
+
+// buildEnglishMorphIndex は正規化した英名からモーフ名への索引を作る。
+func buildEnglishMorphIndex(modelData *model.PmxModel) map[string]string {
+	if modelData == nil || modelData.Morphs == nil {
+		return nil
+	}
+	index := make(map[string]string, modelData.Morphs.Len())
+	for _, morph := range modelData.Morphs.Values() {
+		if morph == nil {
+			continue
+		}
+		key := normalizeEnglishName(morph.EnglishName)
+		if key == "" {
+			continue
+		}
+		if _, exists := index[key]; exists {
+			continue
+		}
+		index[key] = morph.Name()
+	}
+	return index
+}
+
+// normalizeEnglishName は英名照合用に大文字小文字と空白を揃える。
+func normalizeEnglishName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), ""))
+}
+
+// sortNamesByIndex はインデックス順にトラックを並べ替える。
+func sortNamesByIndex(entries []indexedName) []TrackEntry {
 	if len(entries) == 0 {
 		return nil
 	}
@@ -134,19 +296,48 @@ func sortNamesByIndex(entries []indexedName) []string {
 	sort.Slice(copyEntries, func(i, j int) bool {
 		return copyEntries[i].Index < copyEntries[j].Index
 	})
-	out := make([]string, len(copyEntries))
+	out := make([]TrackEntry, len(copyEntries))
 	for i, entry := range copyEntries {
-		out[i] = entry.Name
+		out[i] = entry.Entry
+	}
+	return out
+}
+
+// sortEntriesByKeyCount はキーフレーム数の多い順、同数なら名前順に並べ替える。
+func sortEntriesByKeyCount(entries []TrackEntry) []TrackEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	out := append([]TrackEntry(nil), entries...)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].KeyCount != out[j].KeyCount {
+			return out[i].KeyCount > out[j].KeyCount
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// sortAmbiguousNames は曖昧な切り詰め一致を名前順に並べ替える。
+func sortAmbiguousNames(entries []AmbiguousName) []AmbiguousName {
+	if len(entries) == 0 {
+		return nil
 	}
+	out := append([]AmbiguousName(nil), entries...)
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
 	return out
 }
 
-// sortNamesByName は名前順に並べ替える。
-func sortNamesByName(names []string) []string {
-	if len(names) == 0 {
+// sortMappedNames は対応付け結果を名前順に並べ替える。
+func sortMappedNames(entries []MappedName) []MappedName {
+	if len(entries) == 0 {
 		return nil
 	}
-	out := append([]string(nil), names...)
-	sort.Strings(out)
+	out := append([]MappedName(nil), entries...)
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
 	return out
 }
@@ -0,0 +1,67 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// csvExtension はCSVファイルの拡張子。
+const csvExtension = ".csv"
+
+// CsvExportRequest はモーションのCSV書き出しの入力を表す。
+type CsvExportRequest struct {
+	Motion       *motion.VmdMotion
+	FallbackPath string
+	// Writer はCSVの保存リポジトリ。
+	Writer      moutput.IFileWriter
+	SaveOptions moutput.SaveOptions
+	// Output は保存先のファイル名テンプレート・フォルダ・重複時の扱い。
+	Output OutputPathOptions
+}
+
+// CsvExportResult はモーションのCSV書き出しの結果を表す。
+type CsvExportResult struct {
+	BasePath string
+	CsvPath  string
+	// OutputSkipped は保存先の重複時の扱いにより保存しなかったかを表す。
+	OutputSkipped bool
+}
+
+// ExportCsv はモーションの全キーフレームをCSVとして保存する。
+// ファイル名の {op} は「csv」になり、拡張子は .csv になる。
+func ExportCsv(request CsvExportRequest) (*CsvExportResult, error) {
+	result := &CsvExportResult{}
+	if request.Motion == nil {
+		return result, nil
+	}
+	basePath := request.Motion.Path()
+	if basePath == "" {
+		basePath = request.FallbackPath
+	}
+	result.BasePath = basePath
+	if basePath == "" {
+		return result, nil
+	}
+	if request.Writer == nil {
+		return result, fmt.Errorf("保存リポジトリがありません")
+	}
+
+	output := request.Output
+	output.Extension = csvExtension
+	csvPath, skipped, err := ResolveOutputPath(basePath, OutputOpCsv, output)
+	if err != nil {
+		return result, err
+	}
+	result.CsvPath = csvPath
+	result.OutputSkipped = skipped
+	if csvPath == "" || skipped {
+		return result, nil
+	}
+	if err := request.Writer.Save(csvPath, request.Motion, request.SaveOptions); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/model"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// FittedMotionSaveRequest はモデル適合モーション保存の入力を表す。
+type FittedMotionSaveRequest struct {
+	Motion       *motion.VmdMotion
+	Model        *model.PmxModel
+	FallbackPath string
+	Writer       moutput.IFileWriter
+	SaveOptions  moutput.SaveOptions
+	// CheckOptions はOK/NG判定に使う追加の解決手段。
+	CheckOptions CheckOptions
+	// Output は保存先のファイル名テンプレート・フォルダ・重複時の扱い。
+	Output OutputPathOptions
+}
+
+// FittedMotionSaveResult はモデル適合モーション保存の結果を表す。
+type FittedMotionSaveResult struct {
+	BasePath   string
+	FittedPath string
+	// DroppedBones/DroppedMorphs は取り除いた再生できないトラック（キーフレーム数の多い順）。
+	DroppedBones  []TrackEntry
+	DroppedMorphs []TrackEntry
+	// OutputSkipped は保存先の重複時の扱いにより保存しなかったかを表す。
+	OutputSkipped bool
+}
+
+// BuildFittedMotion はモデルでそのまま再生できないボーン/モーフのトラックを除いたモーションを複製する。
+// 除くトラックは UnplayableTracks で判定する。
+func BuildFittedMotion(modelData *model.PmxModel, source *motion.VmdMotion, options CheckOptions) (*motion.VmdMotion, CheckResult, error) {
+	if source == nil {
+		return nil, CheckResult{}, nil
+	}
+	result, err := CheckExistsWithOptions(modelData, source, options)
+	if err != nil {
+		return nil, CheckResult{}, err
+	}
+	copied, err := source.Copy()
+	if err != nil {
+		return nil, CheckResult{}, err
+	}
+	bones, morphs := UnplayableTracks(result)
+	deleteTracks(&copied, TrackNames(bones), TrackNames(morphs))
+	return &copied, result, nil
+}
+
+// UnplayableTracks はモデルでそのまま再生できないボーン/モーフのトラックを、キーフレーム数の多い順に返す。
+// NGのほか、別名・英名でのみ対応付いたトラックと曖昧な切り詰め一致も含む。切り詰め一致はMMDで再生されるため含まない。
+func UnplayableTracks(result CheckResult) ([]TrackEntry, []TrackEntry) {
+	var bones, morphs []TrackEntry
+	forEachScoredTrack(result, func(entry TrackEntry, kind string, ok bool) {
+		if ok {
+			return
+		}
+		if kind == ScoreKindMorph {
+			morphs = append(morphs, entry)
+		} else {
+			bones = append(bones, entry)
+		}
+	})
+	return sortEntriesByKeyCount(bones), sortEntriesByKeyCount(morphs)
+}
+
+// SaveFittedMotion はモデルで再生できるトラックだけを残したモーションを保存する。
+func SaveFittedMotion(request FittedMotionSaveRequest) (*FittedMotionSaveResult, error) {
+	result := &FittedMotionSaveResult{}
+	if request.Motion == nil {
+		return result, nil
+	}
+	if request.Model == nil {
+		return result, fmt.Errorf("モデルがありません")
+	}
+	basePath := request.Motion.Path()
+	if basePath == "" {
+		basePath = request.FallbackPath
+	}
+	result.BasePath = basePath
+	if basePath == "" {
+		return result, nil
+	}
+	if request.Writer == nil {
+		return result, fmt.Errorf("保存リポジトリがありません")
+	}
+
+	fittedMotion, checkResult, err := BuildFittedMotion(request.Model, request.Motion, request.CheckOptions)
+	if err != nil {
+		return result, err
+	}
+	if fittedMotion == nil {
+		return result, nil
+	}
+	result.DroppedBones, result.DroppedMorphs = UnplayableTracks(checkResult)
+
+	fittedPath, skipped, err := ResolveOutputPath(basePath, OutputOpFit, request.Output)
+	if err != nil {
+		return result, err
+	}
+	result.FittedPath = fittedPath
+	result.OutputSkipped = skipped
+	if fittedPath == "" || skipped {
+		return result, nil
+	}
+	if err := request.Writer.Save(fittedPath, fittedMotion, request.SaveOptions); err != nil {
+		return result, err
+	}
+	return result, nil
+}
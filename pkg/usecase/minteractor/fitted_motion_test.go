@@ -0,0 +1,32 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnplayableTracks(t *testing.T) {
+	entry := func(name string, keyCount int) TrackEntry {
+		return TrackEntry{Name: name, TrackStats: TrackStats{KeyCount: keyCount}}
+	}
+	result := CheckResult{
+		OkBones:        []TrackEntry{entry("センター", 10)},
+		NgBones:        []TrackEntry{entry("NG", 1)},
+		MappedBones:    []MappedName{{TrackEntry: entry("別名", 5), Target: "上半身"}},
+		EnglishBones:   []MappedName{{TrackEntry: entry("neck", 3), Target: "首"}},
+		TruncatedBones: []MappedName{{TrackEntry: entry("切り詰め", 8), Target: "切り詰めボーン"}},
+		AmbiguousBones: []AmbiguousName{{TrackEntry: entry("曖昧", 2)}},
+		OkMorphs:       []TrackEntry{entry("あ", 4)},
+		MappedMorphs:   []MappedName{{TrackEntry: entry("a", 6), Target: "あ"}},
+	}
+
+	bones, morphs := UnplayableTracks(result)
+	// 切り詰め一致はMMDで再生されるため残し、それ以外はキーフレーム数の多い順に並ぶ。
+	if got, want := strings.Join(TrackNames(bones), ","), "別名,neck,曖昧,NG"; got != want {
+		t.Fatalf("ボーン = %s, want %s", got, want)
+	}
+	if got := TrackNames(morphs); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("モーフ = %v, want [a]", got)
+	}
+}
@@ -0,0 +1,163 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"sort"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+)
+
+// frameAdapter はキーフレームの種類ごとの複製・補間の処理を表す。
+type frameAdapter[T motion.IBaseFrame] struct {
+	// interpolate は指定フレームの補間値を返す。
+	interpolate func(frame motion.Frame) T
+	// clone はキーフレームをフレーム番号を変えて複製する。
+	clone func(value T, index motion.Frame) T
+	// splitCurves は source の補間曲線のうち from から to の区間を target に設定する。補間曲線がない種類は nil。
+	splitCurves func(target T, source T, from float64, to float64)
+	// resetCurves は target の補間曲線を線形補間にする。補間曲線がない種類は nil。
+	resetCurves func(target T)
+	// step は補間せず直前のキーの状態が続く種類か（IKフレーム）を表す。
+	step bool
+}
+
+// boneFrameAdapter はボーンキーフレームの処理を返す。
+func boneFrameAdapter(frames *motion.BoneNameFrames) frameAdapter[*motion.BoneFrame] {
+	return frameAdapter[*motion.BoneFrame]{
+		interpolate: frames.Get,
+		clone:       cloneBoneFrame,
+		splitCurves: splitBoneCurves,
+		resetCurves: func(target *motion.BoneFrame) { target.Curves = motion.NewBoneCurves() },
+	}
+}
+
+// morphFrameAdapter はモーフキーフレームの処理を返す。
+func morphFrameAdapter(frames *motion.MorphNameFrames) frameAdapter[*motion.MorphFrame] {
+	return frameAdapter[*motion.MorphFrame]{interpolate: frames.Get, clone: cloneMorphFrame}
+}
+
+// cameraFrameAdapter はカメラキーフレームの処理を返す。
+func cameraFrameAdapter(frames *motion.CameraFrames) frameAdapter[*motion.CameraFrame] {
+	return frameAdapter[*motion.CameraFrame]{
+		interpolate: frames.Get,
+		clone:       cloneCameraFrame,
+		splitCurves: splitCameraCurves,
+		resetCurves: func(target *motion.CameraFrame) { target.Curves = motion.NewCameraCurves() },
+	}
+}
+
+// lightFrameAdapter は照明キーフレームの処理を返す。
+func lightFrameAdapter(frames *motion.LightFrames) frameAdapter[*motion.LightFrame] {
+	return frameAdapter[*motion.LightFrame]{interpolate: frames.Get, clone: cloneLightFrame}
+}
+
+// shadowFrameAdapter はセルフ影キーフレームの処理を返す。
+func shadowFrameAdapter(frames *motion.ShadowFrames) frameAdapter[*motion.ShadowFrame] {
+	return frameAdapter[*motion.ShadowFrame]{interpolate: frames.Get, clone: cloneShadowFrame}
+}
+
+// ikFrameAdapter はIKフレームの処理を返す。
+func ikFrameAdapter() frameAdapter[*motion.IkFrame] {
+	return frameAdapter[*motion.IkFrame]{clone: cloneIkFrame, step: true}
+}
+
+// sortedFrames はキーフレームのフレーム番号を昇順で返し、フレーム番号ごとの値も返す。
+func sortedFrames[T any](frames frameIterator[T]) ([]motion.Frame, map[motion.Frame]T) {
+	indexes := make([]motion.Frame, 0)
+	values := make(map[motion.Frame]T)
+	frames.ForEach(func(frame motion.Frame, value T) bool {
+		indexes = append(indexes, frame)
+		values[frame] = value
+		return true
+	})
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	return indexes, values
+}
+
+// cloneBoneFrame はボーンキーフレームをフレーム番号を変えて複製する。
+func cloneBoneFrame(value *motion.BoneFrame, index motion.Frame) *motion.BoneFrame {
+	if value == nil {
+		return motion.NewBoneFrame(index)
+	}
+	copied := value.Copy()
+	copied.SetIndex(index)
+	return copied
+}
+
+// cloneMorphFrame はモーフキーフレームをフレーム番号を変えて複製する。
+func cloneMorphFrame(value *motion.MorphFrame, index motion.Frame) *motion.MorphFrame {
+	if value == nil {
+		return motion.NewMorphFrame(index)
+	}
+	copied := value.Copy()
+	copied.SetIndex(index)
+	return copied
+}
+
+// cloneCameraFrame はカメラキーフレームをフレーム番号を変えて複製する。
+func cloneCameraFrame(value *motion.CameraFrame, index motion.Frame) *motion.CameraFrame {
+	if value == nil {
+		return motion.NewCameraFrame(index)
+	}
+	copied := value.Copy()
+	copied.SetIndex(index)
+	return copied
+}
+
+// cloneLightFrame は照明キーフレームをフレーム番号を変えて複製する。
+func cloneLightFrame(value *motion.LightFrame, index motion.Frame) *motion.LightFrame {
+	if value == nil {
+		return motion.NewLightFrame(index)
+	}
+	copied := value.Copy()
+	copied.SetIndex(index)
+	return copied
+}
+
+// cloneShadowFrame はセルフ影キーフレームをフレーム番号を変えて複製する。
+func cloneShadowFrame(value *motion.ShadowFrame, index motion.Frame) *motion.ShadowFrame {
+	if value == nil {
+		return motion.NewShadowFrame(index)
+	}
+	copied := value.Copy()
+	copied.SetIndex(index)
+	return copied
+}
+
+// cloneIkFrame はIKフレームをフレーム番号を変えて複製する。
+func cloneIkFrame(value *motion.IkFrame, index motion.Frame) *motion.IkFrame {
+	if value == nil {
+		return motion.NewIkFrame(index)
+	}
+	copied := value.Copy()
+	copied.SetIndex(index)
+	return copied
+}
+
+// splitBoneCurves はボーンの補間曲線の一部を設定する。
+func splitBoneCurves(target *motion.BoneFrame, source *motion.BoneFrame, from float64, to float64) {
+	if target == nil || source == nil || source.Curves == nil {
+		return
+	}
+	curves := motion.NewBoneCurves()
+	curves.TranslateX = subCurve(source.Curves.TranslateX, from, to)
+	curves.TranslateY = subCurve(source.Curves.TranslateY, from, to)
+	curves.TranslateZ = subCurve(source.Curves.TranslateZ, from, to)
+	curves.Rotate = subCurve(source.Curves.Rotate, from, to)
+	target.Curves = curves
+}
+
+// splitCameraCurves はカメラの補間曲線の一部を設定する。
+func splitCameraCurves(target *motion.CameraFrame, source *motion.CameraFrame, from float64, to float64) {
+	if target == nil || source == nil || source.Curves == nil {
+		return
+	}
+	curves := motion.NewCameraCurves()
+	curves.TranslateX = subCurve(source.Curves.TranslateX, from, to)
+	curves.TranslateY = subCurve(source.Curves.TranslateY, from, to)
+	curves.TranslateZ = subCurve(source.Curves.TranslateZ, from, to)
+	curves.Rotate = subCurve(source.Curves.Rotate, from, to)
+	curves.Distance = subCurve(source.Curves.Distance, from, to)
+	curves.ViewOfAngle = subCurve(source.Curves.ViewOfAngle, from, to)
+	target.Curves = curves
+}
@@ -0,0 +1,359 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/model"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+)
+
+// ikBakeTolerance はエフェクタが目標に到達したとみなす距離。
+const ikBakeTolerance = 1e-4
+
+// IkBakeOptions はIK焼き込みの設定を表す。
+type IkBakeOptions struct {
+	// EveryFrame は最初から最後までの全フレームで焼き込むかを表す。
+	// false の場合はいずれかのボーンにキーがあるフレームとIK切替フレームだけで焼き込む。
+	EveryFrame bool
+}
+
+// IkBakeResult はIK焼き込みの結果を表す。
+type IkBakeResult struct {
+	// BakedBones は回転を書き込んだIKリンクボーン名（モデルのインデックス順）。
+	BakedBones []string
+	// FrameCount は焼き込んだフレーム数。
+	FrameCount int
+	// MaxError は焼き込み後のエフェクタと目標の最大距離。
+	MaxError float64
+}
+
+// ikPose は1フレーム分のボーンの姿勢をCPUで計算する。
+// 付与親や物理は考慮せず、親子関係・ボーン位置・キーフレームの移動と回転だけから求める。
+type ikPose struct {
+	bones     []*model.Bone
+	rest      []ikVec
+	translate []ikVec
+	rotate    []ikQuat
+	globalPos []ikVec
+	globalRot []ikQuat
+	stamp     []int
+	gen       int
+}
+
+// newIkPose はモデルのボーン構成から姿勢計算器を作る。
+func newIkPose(modelData *model.PmxModel) *ikPose {
+	values := modelData.Bones.Values()
+	size := 0
+	for _, bone := range values {
+		if bone != nil && bone.Index()+1 > size {
+			size = bone.Index() + 1
+		}
+	}
+	pose := &ikPose{
+		bones:     make([]*model.Bone, size),
+		rest:      make([]ikVec, size),
+		translate: make([]ikVec, size),
+		rotate:    make([]ikQuat, size),
+		globalPos: make([]ikVec, size),
+		globalRot: make([]ikQuat, size),
+		stamp:     make([]int, size),
+		gen:       1,
+	}
+	for _, bone := range values {
+		if bone == nil {
+			continue
+		}
+		pose.bones[bone.Index()] = bone
+		pose.rest[bone.Index()] = newIkVec(bone.Position)
+	}
+	return pose
+}
+
+// load は指定フレームのキーフレーム値を読み込む。
+func (p *ikPose) load(motionData *motion.VmdMotion, frame motion.Frame) {
+	for i, bone := range p.bones {
+		p.translate[i] = ikVec{}
+		p.rotate[i] = ikIdentity
+		if bone == nil || motionData.BoneFrames == nil || !motionData.BoneFrames.Contains(bone.Name()) {
+			continue
+		}
+		frames := motionData.BoneFrames.Get(bone.Name())
+		if frames == nil || frames.Len() == 0 {
+			continue
+		}
+		value := frames.Get(frame)
+		if value == nil {
+			continue
+		}
+		p.translate[i] = newIkVec(value.Position)
+		p.rotate[i] = newIkQuat(value.Rotation)
+	}
+	p.gen++
+}
+
+// setRotation はボーンのローカル回転を変更し、計算済みのグローバル姿勢を無効にする。
+func (p *ikPose) setRotation(index int, rotation ikQuat) {
+	p.rotate[index] = rotation
+	p.gen++
+}
+
+// global はボーンのグローバル位置と回転を返す。親から順に必要な分だけ計算する。
+func (p *ikPose) global(index int) (ikVec, ikQuat) {
+	if index < 0 || index >= len(p.bones) {
+		return ikVec{}, ikIdentity
+	}
+	if p.stamp[index] == p.gen {
+		return p.globalPos[index], p.globalRot[index]
+	}
+	bone := p.bones[index]
+	pos := p.rest[index].add(p.translate[index])
+	rot := p.rotate[index]
+	if bone != nil && bone.ParentIndex >= 0 && bone.ParentIndex < len(p.bones) && bone.ParentIndex != index {
+		parentPos, parentRot := p.global(bone.ParentIndex)
+		offset := p.rest[index].sub(p.rest[bone.ParentIndex]).add(p.translate[index])
+		pos = parentPos.add(parentRot.rotate(offset))
+		rot = parentRot.mul(p.rotate[index])
+	}
+	p.globalPos[index] = pos
+	p.globalRot[index] = rot
+	p.stamp[index] = p.gen
+	return pos, rot
+}
+
+// solve はCCD法でIKを解き、エフェクタと目標の距離を返す。
+func (p *ikPose) solve(ikBone *model.Bone) float64 {
+	ik := ikBone.Ik
+	goal, _ := p.global(ikBone.Index())
+	loopCount := ik.LoopCount
+	if loopCount <= 0 {
+		loopCount = 1
+	}
+	for loop := 0; loop < loopCount; loop++ {
+		for _, link := range ik.Links {
+			if link.BoneIndex < 0 || link.BoneIndex >= len(p.bones) {
+				continue
+			}
+			linkPos, linkRot := p.global(link.BoneIndex)
+			effectorPos, _ := p.global(ik.BoneIndex)
+			inverse := linkRot.inverted()
+			toEffector, ok := inverse.rotate(effectorPos.sub(linkPos)).normalized()
+			if !ok {
+				continue
+			}
+			toGoal, ok := inverse.rotate(goal.sub(linkPos)).normalized()
+			if !ok {
+				continue
+			}
+			angle := math.Acos(math.Max(-1, math.Min(1, toEffector.dot(toGoal))))
+			if angle < 1e-6 {
+				continue
+			}
+			if ik.UnitRotation > 0 && angle > ik.UnitRotation {
+				angle = ik.UnitRotation
+			}
+			axis, ok := toEffector.cross(toGoal).normalized()
+			if !ok {
+				continue
+			}
+			rotation := p.rotate[link.BoneIndex].mul(newIkQuatFromAxisAngle(axis, angle)).normalized()
+			if link.AngleLimit {
+				rotation = limitIkRotation(rotation, newIkVec(link.MinAngleLimit), newIkVec(link.MaxAngleLimit))
+			}
+			p.setRotation(link.BoneIndex, rotation)
+		}
+		effectorPos, _ := p.global(ik.BoneIndex)
+		if effectorPos.sub(goal).length() < ikBakeTolerance {
+			break
+		}
+	}
+	effectorPos, _ := p.global(ik.BoneIndex)
+	return effectorPos.sub(goal).length()
+}
+
+// limitIkRotation は回転を角度制限の範囲に収める。
+// ひざのようにX軸だけが動く制限はX軸回りの回転に限定し、それ以外はオイラー角ごとに制限する。
+func limitIkRotation(rotation ikQuat, minimum ikVec, maximum ikVec) ikQuat {
+	if minimum[1] == 0 && maximum[1] == 0 && minimum[2] == 0 && maximum[2] == 0 {
+		angle := 2 * math.Atan2(rotation.X, rotation.W)
+		if angle > math.Pi {
+			angle -= 2 * math.Pi
+		} else if angle < -math.Pi {
+			angle += 2 * math.Pi
+		}
+		angle = clampAngles(ikVec{angle}, minimum, maximum)[0]
+		return newIkQuatFromAxisAngle(ikVec{1, 0, 0}, angle)
+	}
+	return newIkQuatFromEuler(clampAngles(rotation.euler(), minimum, maximum))
+}
+
+// BakeIk はIKの解をフレームごとにCPUで計算し、IKリンクボーンの回転キーとして書き込んだモーションを複製する。
+// 書き込み後はモデルの全IKボーンを0フレームでOFFにするため、IKを無効にしても同じ見た目になる。
+func BakeIk(modelData *model.PmxModel, source *motion.VmdMotion, options IkBakeOptions) (*motion.VmdMotion, *IkBakeResult, error) {
+	if source == nil {
+		return nil, nil, nil
+	}
+	copied, err := source.Copy()
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := bakeIkInto(modelData, source, &copied, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &copied, result, nil
+}
+
+// bakeIkInto は source を評価した結果を target に書き込む。
+func bakeIkInto(modelData *model.PmxModel, source *motion.VmdMotion, target *motion.VmdMotion, options IkBakeOptions) (*IkBakeResult, error) {
+	if modelData == nil || modelData.Bones == nil {
+		return nil, fmt.Errorf("IKの焼き込みにはモデルが必要です")
+	}
+	ikBones := make([]*model.Bone, 0)
+	for _, bone := range modelData.Bones.Values() {
+		if bone != nil && bone.Ik != nil && len(bone.Ik.Links) > 0 {
+			ikBones = append(ikBones, bone)
+		}
+	}
+	sort.Slice(ikBones, func(i, j int) bool { return ikBones[i].Index() < ikBones[j].Index() })
+	result := &IkBakeResult{}
+	if len(ikBones) == 0 {
+		return result, nil
+	}
+
+	ikStates, err := CheckIkFrames(modelData, source)
+	if err != nil {
+		return nil, err
+	}
+	toggles := make(map[string][]IkToggle, len(ikStates))
+	for _, state := range ikStates {
+		toggles[state.Name] = state.Toggles
+	}
+
+	pose := newIkPose(modelData)
+	frames := collectBakeFrames(source, ikStates, options)
+	baked := make(map[int]struct{})
+	for _, frame := range frames {
+		pose.load(source, frame)
+		solved := make(map[int]struct{})
+		for _, ikBone := range ikBones {
+			if !isIkEnabledAt(toggles[ikBone.Name()], frame) {
+				continue
+			}
+			distance := pose.solve(ikBone)
+			result.MaxError = math.Max(result.MaxError, distance)
+			for _, link := range ikBone.Ik.Links {
+				solved[link.BoneIndex] = struct{}{}
+			}
+		}
+		for index := range solved {
+			bone := pose.bones[index]
+			if bone == nil {
+				continue
+			}
+			writeBakedRotation(target, bone.Name(), frame, pose.rotate[index])
+			baked[index] = struct{}{}
+		}
+	}
+	result.FrameCount = len(frames)
+
+	indexes := make([]int, 0, len(baked))
+	for index := range baked {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		result.BakedBones = append(result.BakedBones, pose.bones[index].Name())
+	}
+
+	disableAllIk(target, ikBones)
+	return result, nil
+}
+
+// collectBakeFrames は焼き込むフレームを昇順で返す。
+func collectBakeFrames(motionData *motion.VmdMotion, ikStates []IkFrameEntry, options IkBakeOptions) []motion.Frame {
+	if options.EveryFrame {
+		start := int(math.Floor(float64(motionData.MinFrame())))
+		end := int(math.Ceil(float64(motionData.MaxFrame())))
+		frames := make([]motion.Frame, 0, end-start+1)
+		for frame := start; frame <= end; frame++ {
+			frames = append(frames, motion.Frame(frame))
+		}
+		return frames
+	}
+	set := make(map[motion.Frame]struct{})
+	set[0] = struct{}{}
+	if motionData.BoneFrames != nil {
+		for _, name := range motionData.BoneFrames.Names() {
+			nameFrames := motionData.BoneFrames.Get(name)
+			if nameFrames == nil {
+				continue
+			}
+			nameFrames.ForEach(func(frame motion.Frame, _ *motion.BoneFrame) bool {
+				set[frame] = struct{}{}
+				return true
+			})
+		}
+	}
+	for _, state := range ikStates {
+		for _, toggle := range state.Toggles {
+			set[toggle.Frame] = struct{}{}
+		}
+	}
+	frames := make([]motion.Frame, 0, len(set))
+	for frame := range set {
+		frames = append(frames, frame)
+	}
+	sort.Slice(frames, func(i, j int) bool { return frames[i] < frames[j] })
+	return frames
+}
+
+// isIkEnabledAt は指定フレームでIKが有効か判定する。切替がない場合は有効とする。
+func isIkEnabledAt(toggles []IkToggle, frame motion.Frame) bool {
+	enabled := true
+	for _, toggle := range toggles {
+		if toggle.Frame > frame {
+			break
+		}
+		enabled = toggle.Enabled
+	}
+	return enabled
+}
+
+// writeBakedRotation は焼き込んだ回転をボーンのキーとして書き込む。
+// 既存キーは回転だけを置き換え、キーがないフレームは補間値の移動を引き継いで追加する。
+func writeBakedRotation(motionData *motion.VmdMotion, name string, frame motion.Frame, rotation ikQuat) {
+	if motionData.BoneFrames == nil {
+		motionData.BoneFrames = motion.NewBoneFrames()
+	}
+	nameFrames := motionData.BoneFrames.Get(name)
+	if nameFrames == nil || !motionData.BoneFrames.Contains(name) {
+		nameFrames = motion.NewBoneNameFrames(name)
+		motionData.BoneFrames.Update(nameFrames)
+	}
+	if nameFrames.Has(frame) {
+		nameFrames.Get(frame).Rotation = rotation.toMmath()
+		return
+	}
+	baked := motion.NewBoneFrame(frame)
+	if nameFrames.Len() > 0 {
+		if interpolated := nameFrames.Get(frame); interpolated != nil && interpolated.Position != nil {
+			baked.Position = interpolated.Position.Copy()
+		}
+	}
+	baked.Rotation = rotation.toMmath()
+	nameFrames.Append(baked)
+}
+
+// disableAllIk はIKフレームを0フレームで全IKボーンをOFFにする1件に置き換える。
+func disableAllIk(motionData *motion.VmdMotion, ikBones []*model.Bone) {
+	ikFrame := motion.NewIkFrame(0)
+	ikFrame.Visible = true
+	for _, bone := range ikBones {
+		ikFrame.IkList = append(ikFrame.IkList, motion.NewIkEnabledFrame(bone.Name(), false))
+	}
+	motionData.IkFrames = motion.NewIkFrames()
+	motionData.IkFrames.Append(ikFrame)
+}
@@ -0,0 +1,247 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+	"github.com/miu200521358/mlib_go/pkg/domain/model"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+)
+
+// 検証用の脚のボーン構成。
+const (
+	testLegIndex   = 0
+	testKneeIndex  = 1
+	testAnkleIndex = 2
+	testLegIkIndex = 3
+	testLegIkName  = "左足ＩＫ"
+)
+
+// testKneeMin/testKneeMax はMMD標準と同じひざの角度制限（X軸のみ -180°〜-0.5°）。
+var (
+	testKneeMin = ikVec{-math.Pi, 0, 0}
+	testKneeMax = ikVec{-0.5 * math.Pi / 180, 0, 0}
+)
+
+// testIkTolerance は焼き込み後のエフェクタの誤差の許容値。
+const testIkTolerance = 1e-3
+
+// newTestLegModel は足・ひざ・足首と足IKだけの脚のモデルを作る。
+func newTestLegModel() *model.PmxModel {
+	modelData := model.NewPmxModel("")
+	specs := []struct {
+		name     string
+		parent   int
+		position mmath.Vec3
+	}{
+		{name: "左足", parent: -1, position: mmath.Vec3{X: 1, Y: 10, Z: 0}},
+		{name: "左ひざ", parent: testLegIndex, position: mmath.Vec3{X: 1, Y: 5, Z: 0}},
+		{name: "左足首", parent: testKneeIndex, position: mmath.Vec3{X: 1, Y: 0, Z: 0}},
+		{name: testLegIkName, parent: -1, position: mmath.Vec3{X: 1, Y: 0, Z: 0}},
+	}
+	for i, spec := range specs {
+		bone := model.NewBoneByName(spec.name)
+		bone.SetIndex(i)
+		bone.ParentIndex = spec.parent
+		position := spec.position
+		bone.Position = &position
+		if i == testLegIkIndex {
+			bone.Ik = &model.Ik{
+				BoneIndex:    testAnkleIndex,
+				LoopCount:    40,
+				UnitRotation: 2,
+				Links: []model.IkLink{
+					{
+						BoneIndex:     testKneeIndex,
+						AngleLimit:    true,
+						MinAngleLimit: &mmath.Vec3{X: testKneeMin[0]},
+						MaxAngleLimit: &mmath.Vec3{X: testKneeMax[0]},
+					},
+					{BoneIndex: testLegIndex},
+				},
+			}
+		}
+		modelData.Bones.Append(bone)
+	}
+	return modelData
+}
+
+// kneeAngle はひざの回転がX軸回りだけか確認し、その角度を返す。
+func kneeAngle(t *testing.T, rotation ikQuat) float64 {
+	t.Helper()
+	if math.Abs(rotation.Y) > 1e-9 || math.Abs(rotation.Z) > 1e-9 {
+		t.Fatalf("ひざがX軸以外に回転しています: %+v", rotation)
+	}
+	return 2 * math.Atan2(rotation.X, rotation.W)
+}
+
+// assertKneeLimited はひざの角度が制限内か確認する。
+func assertKneeLimited(t *testing.T, rotation ikQuat) {
+	t.Helper()
+	angle := kneeAngle(t, rotation)
+	if angle < testKneeMin[0]-1e-9 || angle > testKneeMax[0]+1e-9 {
+		t.Fatalf("ひざの角度が制限外です: %v", angle)
+	}
+}
+
+func TestLimitIkRotationKnee(t *testing.T) {
+	cases := []struct {
+		name     string
+		rotation ikQuat
+		want     float64
+	}{
+		{name: "制限内", rotation: newIkQuatFromAxisAngle(ikVec{1, 0, 0}, -1), want: -1},
+		{name: "逆方向は上限", rotation: newIkQuatFromAxisAngle(ikVec{1, 0, 0}, 0.5), want: testKneeMax[0]},
+		{name: "ねじれは除く", rotation: newIkQuatFromEuler(ikVec{-0.8, 0.3, 0.2}), want: -0.8},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			limited := limitIkRotation(tc.rotation, testKneeMin, testKneeMax)
+			angle := kneeAngle(t, limited)
+			if math.Abs(angle-tc.want) > 0.05 {
+				t.Fatalf("角度 = %v, want %v", angle, tc.want)
+			}
+		})
+	}
+}
+
+func TestLimitIkRotationEuler(t *testing.T) {
+	minimum := ikVec{-0.5, -0.5, -0.5}
+	maximum := ikVec{0.5, 0.5, 0.5}
+	limited := limitIkRotation(newIkQuatFromEuler(ikVec{1, -0.2, 0.9}), minimum, maximum)
+	want := ikVec{0.5, -0.2, 0.5}
+	if got := limited.euler(); got.sub(want).length() > 1e-9 {
+		t.Fatalf("オイラー角 = %v, want %v", got, want)
+	}
+}
+
+func TestIkPoseSolveLegChain(t *testing.T) {
+	modelData := newTestLegModel()
+	ikBone, _ := modelData.Bones.Get(testLegIkIndex)
+	pose := newIkPose(modelData)
+	// 脚の長さ(10)より近い位置へ足IKを動かし、ひざを曲げさせる。
+	pose.translate[testLegIkIndex] = ikVec{0, 4, -2}
+	pose.gen++
+
+	distance := pose.solve(ikBone)
+	if distance > testIkTolerance {
+		t.Fatalf("エフェクタが目標に届いていません: %v", distance)
+	}
+	goal, _ := pose.global(testLegIkIndex)
+	effector, _ := pose.global(testAnkleIndex)
+	if math.Abs(effector.sub(goal).length()-distance) > 1e-9 {
+		t.Fatalf("返した距離 %v と実際の距離 %v が一致しません", distance, effector.sub(goal).length())
+	}
+	assertKneeLimited(t, pose.rotate[testKneeIndex])
+	if angle := kneeAngle(t, pose.rotate[testKneeIndex]); angle > -0.1 {
+		t.Fatalf("ひざが曲がっていません: %v", angle)
+	}
+}
+
+func TestBakeIkLegChain(t *testing.T) {
+	modelData := newTestLegModel()
+	source := motion.NewVmdMotion("")
+	source.BoneFrames = motion.NewBoneFrames()
+	ikFrames := motion.NewBoneNameFrames(testLegIkName)
+	for i, position := range []mmath.Vec3{{X: 0, Y: 4, Z: -2}, {X: 0.5, Y: 3, Z: 1}} {
+		frame := motion.NewBoneFrame(motion.Frame(i * 10))
+		value := position
+		frame.Position = &value
+		ikFrames.Append(frame)
+	}
+	source.BoneFrames.Update(ikFrames)
+
+	baked, result, err := BakeIk(modelData, source, IkBakeOptions{})
+	if err != nil {
+		t.Fatalf("BakeIk: %v", err)
+	}
+	if result.MaxError > testIkTolerance {
+		t.Fatalf("最大誤差が大きすぎます: %v", result.MaxError)
+	}
+	if result.FrameCount != 2 {
+		t.Fatalf("焼き込んだフレーム数 = %d, want 2", result.FrameCount)
+	}
+	if len(result.BakedBones) != 2 || result.BakedBones[0] != "左足" || result.BakedBones[1] != "左ひざ" {
+		t.Fatalf("焼き込んだボーン = %v", result.BakedBones)
+	}
+
+	// IKを使わず、焼き込んだ回転だけで足首が目標に届くか確かめる。
+	pose := newIkPose(modelData)
+	for _, frame := range []motion.Frame{0, 10} {
+		pose.load(baked, frame)
+		goal, _ := pose.global(testLegIkIndex)
+		effector, _ := pose.global(testAnkleIndex)
+		if distance := effector.sub(goal).length(); distance > testIkTolerance {
+			t.Fatalf("%vフレーム: 焼き込み後の誤差が大きすぎます: %v", frame, distance)
+		}
+		assertKneeLimited(t, pose.rotate[testKneeIndex])
+	}
+
+	if baked.IkFrames == nil || baked.IkFrames.Len() != 1 {
+		t.Fatalf("IKフレームがIK OFFの1件になっていません")
+	}
+	ikFrame := baked.IkFrames.Get(0)
+	if len(ikFrame.IkList) != 1 || ikFrame.IkList[0].BoneName != testLegIkName || ikFrame.IkList[0].Enabled {
+		t.Fatalf("IK OFFのフレームが不正です: %+v", ikFrame.IkList)
+	}
+}
+
+func TestBuildSanitizedMotionBakeIkKeepsIkOff(t *testing.T) {
+	modelData := newTestLegModel()
+	source := motion.NewVmdMotion("")
+	source.BoneFrames = motion.NewBoneFrames()
+	ikFrames := motion.NewBoneNameFrames(testLegIkName)
+	frame := motion.NewBoneFrame(0)
+	frame.Position = &mmath.Vec3{X: 0, Y: 4, Z: -2}
+	ikFrames.Append(frame)
+	source.BoneFrames.Update(ikFrames)
+
+	// 画面の表示順（焼き込み→IKフレームの除去）で指定しても、焼き込みで書き込んだIK OFFが残る。
+	operations := []StripOperation{{Kind: StripBakeIk}, {Kind: StripIkFrames}}
+	sanitized, result, err := buildSanitizedMotion(source, operations, SanitizeContext{Model: modelData})
+	if err != nil {
+		t.Fatalf("buildSanitizedMotion: %v", err)
+	}
+	if result == nil || result.MaxError > testIkTolerance {
+		t.Fatalf("焼き込みの結果が不正です: %+v", result)
+	}
+	if sanitized.IkFrames == nil || sanitized.IkFrames.Len() != 1 {
+		t.Fatalf("IK OFFのフレームが除去されています")
+	}
+}
+
+func TestBuildSanitizedMotionBakeIkEveryFrame(t *testing.T) {
+	modelData := newTestLegModel()
+	source := motion.NewVmdMotion("")
+	source.BoneFrames = motion.NewBoneFrames()
+	ikFrames := motion.NewBoneNameFrames(testLegIkName)
+	for i, position := range []mmath.Vec3{{X: 0, Y: 4, Z: -2}, {X: 0.5, Y: 3, Z: 1}} {
+		frame := motion.NewBoneFrame(motion.Frame(i * 10))
+		value := position
+		frame.Position = &value
+		ikFrames.Append(frame)
+	}
+	source.BoneFrames.Update(ikFrames)
+
+	// 設定文字列を経由しても全フレームの焼き込みが引き継がれる。
+	values := FormatStripOperations([]StripOperation{{Kind: StripBakeIk, EveryFrame: true}})
+	if len(values) != 1 || values[0] != "bakeik=every" {
+		t.Fatalf("設定文字列 = %v", values)
+	}
+	operations, err := ParseStripOperations(values)
+	if err != nil {
+		t.Fatalf("ParseStripOperations: %v", err)
+	}
+	if len(operations) != 1 || !operations[0].EveryFrame {
+		t.Fatalf("復元した操作 = %+v", operations)
+	}
+	_, result, err := buildSanitizedMotion(source, operations, SanitizeContext{Model: modelData})
+	if err != nil {
+		t.Fatalf("buildSanitizedMotion: %v", err)
+	}
+	if result == nil || result.FrameCount != 11 {
+		t.Fatalf("焼き込んだフレーム数が全フレーム分ではありません: %+v", result)
+	}
+}
@@ -0,0 +1,91 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"github.com/miu200521358/mlib_go/pkg/domain/model"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+)
+
+// IkStatus はIK切替対象ボーンのモデル上の状態を表す。
+// 値は日本語の説明文で、メッセージキーとしても使う。
+type IkStatus string
+
+// IK切替対象ボーンの状態一覧。
+const (
+	IkStatusOk      IkStatus = "IKボーンとして存在します"
+	IkStatusNotIk   IkStatus = "ボーンはありますがIKではないため、IK切替は無視されます"
+	IkStatusMissing IkStatus = "モデルにないボーンのため、IK切替は無視されます"
+)
+
+// IkToggle はIKのON/OFFが切り替わったフレームを表す。
+type IkToggle struct {
+	Frame   motion.Frame
+	Enabled bool
+}
+
+// IkFrameEntry はモーションでIKを切り替えているボーンの判定結果を表す。
+type IkFrameEntry struct {
+	Name   string
+	Status IkStatus
+	// KeyCount はこのボーンを含むIKキーフレームの数。
+	KeyCount int
+	// Toggles は直前の状態（初期状態はON）から切り替わったフレーム。
+	Toggles []IkToggle
+}
+
+// IsOk はモデルのIKボーンとして切り替えが反映されるか判定する。
+func (e IkFrameEntry) IsOk() bool {
+	return e.Status == IkStatusOk
+}
+
+// CheckIkFrames はモーションのIKキーフレームに含まれるボーンがモデルのIKボーンとして存在するか判定する。
+// 結果はモーションに初めて登場した順に並ぶ。
+func CheckIkFrames(modelData *model.PmxModel, motionData *motion.VmdMotion) ([]IkFrameEntry, error) {
+	if motionData == nil || motionData.IkFrames == nil || motionData.IkFrames.Len() == 0 {
+		return nil, nil
+	}
+	entries := make([]IkFrameEntry, 0)
+	indexes := make(map[string]int)
+	motionData.IkFrames.ForEach(func(frame motion.Frame, ikFrame *motion.IkFrame) bool {
+		if ikFrame == nil {
+			return true
+		}
+		for _, ik := range ikFrame.IkList {
+			if ik == nil || ik.BoneName == "" {
+				continue
+			}
+			index, ok := indexes[ik.BoneName]
+			if !ok {
+				index = len(entries)
+				indexes[ik.BoneName] = index
+				entries = append(entries, IkFrameEntry{Name: ik.BoneName})
+			}
+			entry := &entries[index]
+			entry.KeyCount++
+			enabled := true
+			if len(entry.Toggles) > 0 {
+				enabled = entry.Toggles[len(entry.Toggles)-1].Enabled
+			}
+			if ik.Enabled != enabled {
+				entry.Toggles = append(entry.Toggles, IkToggle{Frame: frame, Enabled: ik.Enabled})
+			}
+		}
+		return true
+	})
+
+	for i := range entries {
+		bone, ok, err := resolveBone(modelData, entries[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !ok || bone == nil:
+			entries[i].Status = IkStatusMissing
+		case bone.Ik == nil:
+			entries[i].Status = IkStatusNotIk
+		default:
+			entries[i].Status = IkStatusOk
+		}
+	}
+	return entries, nil
+}
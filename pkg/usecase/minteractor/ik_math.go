@@ -0,0 +1,159 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"math"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+)
+
+// ikVec はIK計算用の3次元ベクトルを表す。
+type ikVec [3]float64
+
+// ikQuat はIK計算用の単位クォータニオンを表す。
+type ikQuat struct {
+	X, Y, Z, W float64
+}
+
+// ikIdentity は回転なしのクォータニオン。
+var ikIdentity = ikQuat{W: 1}
+
+// newIkVec はmmathのベクトルから変換する。nilの場合は零ベクトルを返す。
+func newIkVec(v *mmath.Vec3) ikVec {
+	if v == nil {
+		return ikVec{}
+	}
+	return ikVec{v.X, v.Y, v.Z}
+}
+
+// newIkQuat はmmathのクォータニオンから変換する。nilの場合は回転なしを返す。
+func newIkQuat(q *mmath.Quaternion) ikQuat {
+	if q == nil {
+		return ikIdentity
+	}
+	return ikQuat{X: q.X(), Y: q.Y(), Z: q.Z(), W: q.W()}.normalized()
+}
+
+// toMmath はmmathのクォータニオンに変換する。
+func (q ikQuat) toMmath() *mmath.Quaternion {
+	return mmath.NewQuaternionByValues(q.X, q.Y, q.Z, q.W)
+}
+
+func (a ikVec) add(b ikVec) ikVec { return ikVec{a[0] + b[0], a[1] + b[1], a[2] + b[2]} }
+func (a ikVec) sub(b ikVec) ikVec { return ikVec{a[0] - b[0], a[1] - b[1], a[2] - b[2]} }
+func (a ikVec) dot(b ikVec) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+func (a ikVec) cross(b ikVec) ikVec {
+	return ikVec{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+func (a ikVec) length() float64 { return math.Sqrt(a.dot(a)) }
+
+// normalized は長さ1に正規化したベクトルを返す。長さ0の場合は false を返す。
+func (a ikVec) normalized() (ikVec, bool) {
+	length := a.length()
+	if length < 1e-9 {
+		return ikVec{}, false
+	}
+	return ikVec{a[0] / length, a[1] / length, a[2] / length}, true
+}
+
+// mul はクォータニオンの積 q*r を返す（rを先に適用する）。
+func (q ikQuat) mul(r ikQuat) ikQuat {
+	return ikQuat{
+		X: q.W*r.X + q.X*r.W + q.Y*r.Z - q.Z*r.Y,
+		Y: q.W*r.Y - q.X*r.Z + q.Y*r.W + q.Z*r.X,
+		Z: q.W*r.Z + q.X*r.Y - q.Y*r.X + q.Z*r.W,
+		W: q.W*r.W - q.X*r.X - q.Y*r.Y - q.Z*r.Z,
+	}
+}
+
+// inverted は単位クォータニオンの逆回転を返す。
+func (q ikQuat) inverted() ikQuat {
+	return ikQuat{X: -q.X, Y: -q.Y, Z: -q.Z, W: q.W}
+}
+
+// normalized は長さ1に正規化したクォータニオンを返す。
+func (q ikQuat) normalized() ikQuat {
+	length := math.Sqrt(q.X*q.X + q.Y*q.Y + q.Z*q.Z + q.W*q.W)
+	if length < 1e-12 {
+		return ikIdentity
+	}
+	return ikQuat{X: q.X / length, Y: q.Y / length, Z: q.Z / length, W: q.W / length}
+}
+
+// dot はクォータニオンの内積を返す。
+func (q ikQuat) dot(r ikQuat) float64 {
+	return q.X*r.X + q.Y*r.Y + q.Z*r.Z + q.W*r.W
+}
+
+// angleTo は r までの回転角(ラジアン)を返す。符号違いの同じ回転は0とする。
+func (q ikQuat) angleTo(r ikQuat) float64 {
+	return 2 * math.Acos(math.Min(1, math.Abs(q.dot(r))))
+}
+
+// slerp は r へ t の割合で球面線形補間する。
+func (q ikQuat) slerp(r ikQuat, t float64) ikQuat {
+	cos := q.dot(r)
+	if cos < 0 {
+		r = ikQuat{X: -r.X, Y: -r.Y, Z: -r.Z, W: -r.W}
+		cos = -cos
+	}
+	if cos > 1-1e-9 {
+		return ikQuat{
+			X: q.X + (r.X-q.X)*t,
+			Y: q.Y + (r.Y-q.Y)*t,
+			Z: q.Z + (r.Z-q.Z)*t,
+			W: q.W + (r.W-q.W)*t,
+		}.normalized()
+	}
+	angle := math.Acos(cos)
+	sin := math.Sin(angle)
+	a := math.Sin((1-t)*angle) / sin
+	b := math.Sin(t*angle) / sin
+	return ikQuat{X: q.X*a + r.X*b, Y: q.Y*a + r.Y*b, Z: q.Z*a + r.Z*b, W: q.W*a + r.W*b}
+}
+
+// rotate はベクトルを回転する。
+func (q ikQuat) rotate(v ikVec) ikVec {
+	u := ikVec{q.X, q.Y, q.Z}
+	t := u.cross(v)
+	t = ikVec{2 * t[0], 2 * t[1], 2 * t[2]}
+	c := u.cross(t)
+	return ikVec{v[0] + q.W*t[0] + c[0], v[1] + q.W*t[1] + c[1], v[2] + q.W*t[2] + c[2]}
+}
+
+// newIkQuatFromAxisAngle は正規化済みの軸と角度(ラジアン)から回転を作る。
+func newIkQuatFromAxisAngle(axis ikVec, angle float64) ikQuat {
+	s := math.Sin(angle / 2)
+	return ikQuat{X: axis[0] * s, Y: axis[1] * s, Z: axis[2] * s, W: math.Cos(angle / 2)}
+}
+
+// newIkQuatFromEuler はX→Y→Zの順に回転するオイラー角(ラジアン)から回転を作る。
+func newIkQuatFromEuler(angles ikVec) ikQuat {
+	x := newIkQuatFromAxisAngle(ikVec{1, 0, 0}, angles[0])
+	y := newIkQuatFromAxisAngle(ikVec{0, 1, 0}, angles[1])
+	z := newIkQuatFromAxisAngle(ikVec{0, 0, 1}, angles[2])
+	return z.mul(y).mul(x)
+}
+
+// euler は newIkQuatFromEuler と同じ回転順のオイラー角(ラジアン)を返す。
+func (q ikQuat) euler() ikVec {
+	sinY := 2 * (q.W*q.Y - q.Z*q.X)
+	sinY = math.Max(-1, math.Min(1, sinY))
+	return ikVec{
+		math.Atan2(2*(q.W*q.X+q.Y*q.Z), 1-2*(q.X*q.X+q.Y*q.Y)),
+		math.Asin(sinY),
+		math.Atan2(2*(q.W*q.Z+q.X*q.Y), 1-2*(q.Y*q.Y+q.Z*q.Z)),
+	}
+}
+
+// clampAngles は各軸の角度を下限と上限の範囲に収める。
+func clampAngles(angles ikVec, minimum ikVec, maximum ikVec) ikVec {
+	for i := range angles {
+		low := math.Min(minimum[i], maximum[i])
+		high := math.Max(minimum[i], maximum[i])
+		angles[i] = math.Max(low, math.Min(high, angles[i]))
+	}
+	return angles
+}
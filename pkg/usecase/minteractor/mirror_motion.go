@@ -0,0 +1,254 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+	"github.com/miu200521358/mlib_go/pkg/domain/model"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// mirrorMorphNames は左右表記が「左」「右」の入れ替えにならない標準モーフの、正規化した名前での対応。
+// 標準モデルでは左目のウィンクに左右表記がなく、右目は末尾に「右」を付ける。
+var mirrorMorphNames = map[string]string{
+	"ウィンク":   "ウィンク右",
+	"ウィンク右":  "ウィンク",
+	"ウィンク2":  "ウィンク2右",
+	"ウィンク2右": "ウィンク2",
+}
+
+// MirrorMotionSaveRequest は左右反転モーション保存の入力を表す。
+type MirrorMotionSaveRequest struct {
+	Motion       *motion.VmdMotion
+	FallbackPath string
+	Writer       moutput.IFileWriter
+	SaveOptions  moutput.SaveOptions
+	// Model は反転先のボーン/モーフがあるか確認するモデル。nil の場合はモーション内のトラックで確認する。
+	Model *model.PmxModel
+	// Output は保存先のファイル名テンプレート・フォルダ・重複時の扱い。
+	Output OutputPathOptions
+}
+
+// MirrorMotionSaveResult は左右反転モーション保存の結果を表す。
+type MirrorMotionSaveResult struct {
+	BasePath   string
+	MirrorPath string
+	// Swapped は左右を入れ替えたトラック。
+	Swapped []RenamedTrack
+	// Unpaired は反転先のボーン/モーフがないトラック。反転先の名前で保存する。
+	// Conflict が true のものは別のトラックと反転先が重なったため保存しない。
+	Unpaired []RenamedTrack
+	// OutputSkipped は保存先の重複時の扱いにより保存しなかったかを表す。
+	OutputSkipped bool
+}
+
+// BuildMirroredMotion はボーン/モーフのトラックとIKの左右を入れ替え、YZ平面で反転したモーションを複製する。
+// ボーンは位置のXと回転のY/Zの符号を反転する。左右表記のないトラックも同じ名前のまま反転する。
+// 反転先がモデル（モデルがない場合はモーション）にないトラックは unpaired として返す。
+func BuildMirroredMotion(source *motion.VmdMotion, modelData *model.PmxModel) (*motion.VmdMotion, []RenamedTrack, []RenamedTrack, error) {
+	if source == nil {
+		return nil, nil, nil, nil
+	}
+	copied, err := source.Copy()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	swapped := make([]RenamedTrack, 0)
+	unpaired := make([]RenamedTrack, 0)
+
+	copied.BoneFrames = motion.NewBoneFrames()
+	if source.BoneFrames != nil {
+		for _, name := range source.BoneFrames.Names() {
+			frames := source.BoneFrames.Get(name)
+			if frames == nil {
+				continue
+			}
+			to := mirrorBoneName(name)
+			track := RenamedTrack{Kind: ScoreKindBone, From: name, To: to, Conflict: copied.BoneFrames.Contains(to)}
+			if track.Conflict {
+				// 別名から同じ反転先になった場合は、先に反転したトラックを残す。
+				unpaired = append(unpaired, track)
+				continue
+			}
+			mirrored := motion.NewBoneNameFrames(to)
+			frames.ForEach(func(frame motion.Frame, value *motion.BoneFrame) bool {
+				if value == nil {
+					return true
+				}
+				mirrored.Append(mirrorBoneFrame(cloneBoneFrame(value, frame)))
+				return true
+			})
+			copied.BoneFrames.Update(mirrored)
+			if to == name {
+				continue
+			}
+			swapped = append(swapped, track)
+			exists, err := mirrorTargetExists(modelData, source.BoneFrames.Contains, resolveBone, to)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if !exists {
+				unpaired = append(unpaired, track)
+			}
+		}
+	}
+
+	copied.MorphFrames = motion.NewMorphFrames()
+	if source.MorphFrames != nil {
+		for _, name := range source.MorphFrames.Names() {
+			frames := source.MorphFrames.Get(name)
+			if frames == nil {
+				continue
+			}
+			to := mirrorMorphName(name)
+			track := RenamedTrack{Kind: ScoreKindMorph, From: name, To: to, Conflict: copied.MorphFrames.Contains(to)}
+			if track.Conflict {
+				unpaired = append(unpaired, track)
+				continue
+			}
+			mirrored := motion.NewMorphNameFrames(to)
+			frames.ForEach(func(frame motion.Frame, value *motion.MorphFrame) bool {
+				if value == nil {
+					return true
+				}
+				mirrored.Append(cloneMorphFrame(value, frame))
+				return true
+			})
+			copied.MorphFrames.Update(mirrored)
+			if to == name {
+				continue
+			}
+			swapped = append(swapped, track)
+			exists, err := mirrorTargetExists(modelData, source.MorphFrames.Contains, resolveMorph, to)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if !exists {
+				unpaired = append(unpaired, track)
+			}
+		}
+	}
+
+	copied.IkFrames = motion.NewIkFrames()
+	if source.IkFrames != nil {
+		source.IkFrames.ForEach(func(frame motion.Frame, value *motion.IkFrame) bool {
+			if value == nil {
+				return true
+			}
+			mirrored := cloneIkFrame(value, frame)
+			list := make([]*motion.IkEnabledFrame, 0, len(value.IkList))
+			for _, ik := range value.IkList {
+				if ik == nil {
+					continue
+				}
+				list = append(list, motion.NewIkEnabledFrame(mirrorBoneName(ik.BoneName), ik.Enabled))
+			}
+			mirrored.IkList = list
+			copied.IkFrames.Append(mirrored)
+			return true
+		})
+	}
+
+	if len(swapped) == 0 {
+		swapped = nil
+	}
+	if len(unpaired) == 0 {
+		unpaired = nil
+	}
+	return &copied, swapped, unpaired, nil
+}
+
+// SaveMirroredMotion は左右反転したモーションを保存する。
+func SaveMirroredMotion(request MirrorMotionSaveRequest) (*MirrorMotionSaveResult, error) {
+	result := &MirrorMotionSaveResult{}
+	if request.Motion == nil {
+		return result, nil
+	}
+	basePath := request.Motion.Path()
+	if basePath == "" {
+		basePath = request.FallbackPath
+	}
+	result.BasePath = basePath
+	if basePath == "" {
+		return result, nil
+	}
+	if request.Writer == nil {
+		return result, fmt.Errorf("保存リポジトリがありません")
+	}
+
+	mirroredMotion, swapped, unpaired, err := BuildMirroredMotion(request.Motion, request.Model)
+	if err != nil {
+		return result, err
+	}
+	if mirroredMotion == nil {
+		return result, nil
+	}
+	result.Swapped = swapped
+	result.Unpaired = unpaired
+
+	mirrorPath, skipped, err := ResolveOutputPath(basePath, OutputOpMirror, request.Output)
+	if err != nil {
+		return result, err
+	}
+	result.MirrorPath = mirrorPath
+	result.OutputSkipped = skipped
+	if mirrorPath == "" || skipped {
+		return result, nil
+	}
+	if err := request.Writer.Save(mirrorPath, mirroredMotion, request.SaveOptions); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// mirrorBoneName はボーン名の「左」と「右」を入れ替える。
+// 「左腕」のような接頭辞のほか、「腰キャンセル左」のような準標準ボーンの接尾辞にも対応する。
+func mirrorBoneName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '左':
+			return '右'
+		case '右':
+			return '左'
+		}
+		return r
+	}, name)
+}
+
+// mirrorMorphName はモーフ名の左右を入れ替える。
+// ウィンクは全角/半角を揃えて照合し、元の名前の表記のまま末尾の「右」を付け外しする。
+func mirrorMorphName(name string) string {
+	normalized := normalizeName(name)
+	if mirrored, ok := mirrorMorphNames[normalized.Core]; ok && normalized.Side == 0 {
+		if strings.HasSuffix(mirrored, "右") {
+			return name + "右"
+		}
+		return strings.TrimSuffix(name, "右")
+	}
+	return mirrorBoneName(name)
+}
+
+// mirrorBoneFrame はボーンキーフレームをYZ平面で反転する。補間曲線は時間方向の形なのでそのまま使う。
+func mirrorBoneFrame(value *motion.BoneFrame) *motion.BoneFrame {
+	if value.Position != nil {
+		value.Position = &mmath.Vec3{X: -value.Position.X, Y: value.Position.Y, Z: value.Position.Z}
+	}
+	if value.Rotation != nil {
+		value.Rotation = mmath.NewQuaternionByValues(
+			value.Rotation.X(), -value.Rotation.Y(), -value.Rotation.Z(), value.Rotation.W())
+	}
+	return value
+}
+
+// mirrorTargetExists は反転先の名前がモデルにあるか判定する。モデルがない場合はモーションのトラックで判定する。
+func mirrorTargetExists[T any](modelData *model.PmxModel, contains func(string) bool,
+	resolve func(*model.PmxModel, string) (T, bool, error), name string) (bool, error) {
+	if modelData == nil {
+		return contains(name), nil
+	}
+	_, exists, err := resolve(modelData, name)
+	return exists, err
+}
@@ -0,0 +1,27 @@
+// 指示: miu200521358
+package minteractor
+
+import "testing"
+
+func TestMirrorMorphName(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{name: "ウィンク", want: "ウィンク右"},
+		{name: "ウィンク右", want: "ウィンク"},
+		{name: "ウィンク２", want: "ウィンク２右"},
+		{name: "ウィンク２右", want: "ウィンク２"},
+		// 半角の数字やカナも照合し、反転先は元の表記のままにする。
+		{name: "ウィンク2", want: "ウィンク2右"},
+		{name: "ウィンク2右", want: "ウィンク2"},
+		{name: "ｳｨﾝｸ", want: "ｳｨﾝｸ右"},
+		{name: "左ウィンク", want: "右ウィンク"},
+		{name: "あ", want: "あ"},
+	}
+	for _, tc := range cases {
+		if got := mirrorMorphName(tc.name); got != tc.want {
+			t.Errorf("mirrorMorphName(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"strings"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/model"
+)
+
+// MorphGroup はモーフの操作パネル区分を表す。
+// 値は日本語の表示名で、メッセージキーとしても使う。
+type MorphGroup string
+
+// モーフの操作パネル区分一覧。
+const (
+	MorphGroupEye   MorphGroup = "目"
+	MorphGroupBrow  MorphGroup = "眉"
+	MorphGroupMouth MorphGroup = "口"
+	MorphGroupOther MorphGroup = "その他"
+)
+
+// MorphGroupOrder は表示に使う操作パネル区分の順番。
+var MorphGroupOrder = []MorphGroup{MorphGroupEye, MorphGroupBrow, MorphGroupMouth, MorphGroupOther}
+
+// PMXのモーフ操作パネル値。
+const (
+	pmxPanelBrow  = 1
+	pmxPanelEye   = 2
+	pmxPanelMouth = 3
+)
+
+// MorphPanelGroup は操作パネル区分ごとのモーフ一覧を表す。
+type MorphPanelGroup struct {
+	Group   MorphGroup
+	Entries []TrackEntry
+}
+
+// LipSyncEffect はリップシンク/まばたきモーフ欠落時に見た目へ出る影響を表す。
+// 値は日本語の説明文で、メッセージキーとしても使う。
+type LipSyncEffect string
+
+// リップシンク監査の影響一覧。
+const (
+	LipSyncEffectVowel LipSyncEffect = "母音モーフのキーが失われ、口パクが再生されません"
+	LipSyncEffectBlink LipSyncEffect = "まばたきモーフのキーが失われ、目が開いたままになります"
+)
+
+// LipSyncAuditEntry はモーションが動かしているがモデルにないリップシンク/まばたきモーフを表す。
+type LipSyncAuditEntry struct {
+	TrackEntry
+	Effect LipSyncEffect
+}
+
+// lipSyncMorphs は正規化したモーフ名ごとのリップシンク/まばたきの区分。
+var lipSyncMorphs = map[string]LipSyncEffect{
+	"あ":      LipSyncEffectVowel,
+	"い":      LipSyncEffectVowel,
+	"う":      LipSyncEffectVowel,
+	"え":      LipSyncEffectVowel,
+	"お":      LipSyncEffectVowel,
+	"まばたき":   LipSyncEffectBlink,
+	"ウィンク":   LipSyncEffectBlink,
+	"ウィンク右":  LipSyncEffectBlink,
+	"ウィンク2":  LipSyncEffectBlink,
+	"ウィンク2右": LipSyncEffectBlink,
+}
+
+// morphGroupKeywords はモデルにないモーフの操作パネル区分を名前から推定するための語句。
+// 「真面目」を眉と判定するため、眉を目より先に照合する。
+var morphGroupKeywords = []struct {
+	Group    MorphGroup
+	Keywords []string
+}{
+	{Group: MorphGroupBrow, Keywords: []string{"眉", "真面目", "困る", "にこり", "怒り"}},
+	{Group: MorphGroupEye, Keywords: []string{"目", "まばたき", "ウィンク", "笑い", "なごみ", "びっくり", "じと", "はぅ", "はちゅ", "瞳", "キリッ"}},
+	{Group: MorphGroupMouth, Keywords: []string{"口", "ワ", "ω", "▲", "∧", "□", "舌", "歯", "にやり", "ぺろっ", "はんっ", "えー"}},
+}
+
+// morphGroupExactNames は語句の部分一致では判定できないモーフ名の区分。
+var morphGroupExactNames = map[string]MorphGroup{
+	"上": MorphGroupBrow,
+	"下": MorphGroupBrow,
+	"前": MorphGroupBrow,
+	"あ": MorphGroupMouth,
+	"い": MorphGroupMouth,
+	"う": MorphGroupMouth,
+	"え": MorphGroupMouth,
+	"お": MorphGroupMouth,
+	"ん": MorphGroupMouth,
+}
+
+// GroupMorphsByPanel はモーフ一覧をモデルの操作パネル区分ごとにまとめる。
+// モデルにないモーフは名前から区分を推定する。各区分内の順番は元の順番を保つ。
+func GroupMorphsByPanel(modelData *model.PmxModel, entries []TrackEntry) ([]MorphPanelGroup, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	grouped := make(map[MorphGroup][]TrackEntry, len(MorphGroupOrder))
+	for _, entry := range entries {
+		group, err := morphGroupOf(modelData, entry.Name)
+		if err != nil {
+			return nil, err
+		}
+		grouped[group] = append(grouped[group], entry)
+	}
+	groups := make([]MorphPanelGroup, 0, len(grouped))
+	for _, group := range MorphGroupOrder {
+		if len(grouped[group]) == 0 {
+			continue
+		}
+		groups = append(groups, MorphPanelGroup{Group: group, Entries: grouped[group]})
+	}
+	return groups, nil
+}
+
+// AuditLipSync はNGモーフのうち、リップシンク/まばたきに使うモーフを抽出する。
+func AuditLipSync(ngMorphs []TrackEntry) []LipSyncAuditEntry {
+	entries := make([]LipSyncAuditEntry, 0)
+	for _, entry := range ngMorphs {
+		effect, ok := lipSyncMorphs[normalizeName(entry.Name).Core]
+		if !ok {
+			continue
+		}
+		entries = append(entries, LipSyncAuditEntry{TrackEntry: entry, Effect: effect})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return entries
+}
+
+// morphGroupOf はモーフの操作パネル区分を返す。
+func morphGroupOf(modelData *model.PmxModel, name string) (MorphGroup, error) {
+	morph, ok, err := resolveMorph(modelData, name)
+	if err != nil {
+		return "", err
+	}
+	if !ok || morph == nil {
+		return inferMorphGroup(name), nil
+	}
+	switch int(morph.Panel) {
+	case pmxPanelEye:
+		return MorphGroupEye, nil
+	case pmxPanelBrow:
+		return MorphGroupBrow, nil
+	case pmxPanelMouth:
+		return MorphGroupMouth, nil
+	default:
+		return MorphGroupOther, nil
+	}
+}
+
+// inferMorphGroup はモーフ名から操作パネル区分を推定する。
+func inferMorphGroup(name string) MorphGroup {
+	core := normalizeName(name).Core
+	if group, ok := morphGroupExactNames[core]; ok {
+		return group
+	}
+	for _, group := range morphGroupKeywords {
+		for _, keyword := range group.Keywords {
+			if strings.Contains(core, keyword) {
+				return group.Group
+			}
+		}
+	}
+	return MorphGroupOther
+}
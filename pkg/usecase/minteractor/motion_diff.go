@@ -0,0 +1,383 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"math"
+	"sort"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+)
+
+// DiffSection はモーション差分の区分を表す。
+type DiffSection string
+
+// モーション差分の区分一覧。
+const (
+	DiffSectionBone   DiffSection = "bone"
+	DiffSectionMorph  DiffSection = "morph"
+	DiffSectionIk     DiffSection = "ik"
+	DiffSectionCamera DiffSection = "camera"
+	DiffSectionLight  DiffSection = "light"
+	DiffSectionShadow DiffSection = "shadow"
+)
+
+// DiffSections は差分の表示順。
+var DiffSections = []DiffSection{
+	DiffSectionBone,
+	DiffSectionMorph,
+	DiffSectionIk,
+	DiffSectionCamera,
+	DiffSectionLight,
+	DiffSectionShadow,
+}
+
+// diffTolerance は値が変わったとみなさない誤差。
+const diffTolerance = 1e-6
+
+// TrackDiff は1トラック分のキーフレームの差分を表す。
+// ボーン/モーフ以外の区分は1区分を1トラックとし、Name は空とする。
+type TrackDiff struct {
+	Section DiffSection
+	Name    string
+	// Before/After は変換前後のキーフレーム数。
+	Before int
+	After  int
+	// Added は変換後にだけあるキーフレーム数。
+	Added int
+	// Removed は変換前にだけあるキーフレーム数。
+	Removed int
+	// Changed は同じフレームで値が変わったキーフレーム数。
+	Changed int
+}
+
+// HasChanges は差分があるか判定する。
+func (d TrackDiff) HasChanges() bool {
+	return d.Added > 0 || d.Removed > 0 || d.Changed > 0
+}
+
+// MotionDiff はモーション全体の差分を表す。
+type MotionDiff struct {
+	// Tracks は差分があるトラック（区分の表示順、ボーン/モーフは名前順）。
+	Tracks []TrackDiff
+}
+
+// HasChanges は差分があるか判定する。
+func (d MotionDiff) HasChanges() bool {
+	return len(d.Tracks) > 0
+}
+
+// SectionTotals は差分のある区分ごとの合計を表示順で返す。
+func (d MotionDiff) SectionTotals() []TrackDiff {
+	totals := make(map[DiffSection]*TrackDiff, len(DiffSections))
+	for _, track := range d.Tracks {
+		total, ok := totals[track.Section]
+		if !ok {
+			total = &TrackDiff{Section: track.Section}
+			totals[track.Section] = total
+		}
+		total.Before += track.Before
+		total.After += track.After
+		total.Added += track.Added
+		total.Removed += track.Removed
+		total.Changed += track.Changed
+	}
+	out := make([]TrackDiff, 0, len(totals))
+	for _, section := range DiffSections {
+		if total, ok := totals[section]; ok {
+			out = append(out, *total)
+		}
+	}
+	return out
+}
+
+// DiffMotion は変換前後のモーションをトラックごとに比較する。
+func DiffMotion(source *motion.VmdMotion, transformed *motion.VmdMotion) MotionDiff {
+	if source == nil {
+		source = &motion.VmdMotion{}
+	}
+	if transformed == nil {
+		transformed = &motion.VmdMotion{}
+	}
+	diff := MotionDiff{}
+	appendDiff := func(track TrackDiff) {
+		if track.HasChanges() {
+			diff.Tracks = append(diff.Tracks, track)
+		}
+	}
+
+	for _, name := range unionNames(boneTrackNames(source), boneTrackNames(transformed)) {
+		track := diffFrameMaps(boneFrameMap(source, name), boneFrameMap(transformed, name), equalBoneFrame)
+		track.Section = DiffSectionBone
+		track.Name = name
+		appendDiff(track)
+	}
+	for _, name := range unionNames(morphTrackNames(source), morphTrackNames(transformed)) {
+		track := diffFrameMaps(morphFrameMap(source, name), morphFrameMap(transformed, name), equalMorphFrame)
+		track.Section = DiffSectionMorph
+		track.Name = name
+		appendDiff(track)
+	}
+
+	ikDiff := diffFrameMaps(ikFrameMap(source), ikFrameMap(transformed), equalIkFrame)
+	ikDiff.Section = DiffSectionIk
+	appendDiff(ikDiff)
+	cameraDiff := diffFrameMaps(cameraFrameMap(source), cameraFrameMap(transformed), equalCameraFrame)
+	cameraDiff.Section = DiffSectionCamera
+	appendDiff(cameraDiff)
+	lightDiff := diffFrameMaps(lightFrameMap(source), lightFrameMap(transformed), equalLightFrame)
+	lightDiff.Section = DiffSectionLight
+	appendDiff(lightDiff)
+	shadowDiff := diffFrameMaps(shadowFrameMap(source), shadowFrameMap(transformed), equalShadowFrame)
+	shadowDiff.Section = DiffSectionShadow
+	appendDiff(shadowDiff)
+	return diff
+}
+
+// diffFrameMaps はフレーム番号ごとのキーフレームを比較する。
+func diffFrameMaps[T any](before map[motion.Frame]T, after map[motion.Frame]T, equal func(a T, b T) bool) TrackDiff {
+	track := TrackDiff{Before: len(before), After: len(after)}
+	for frame, value := range before {
+		other, ok := after[frame]
+		if !ok {
+			track.Removed++
+			continue
+		}
+		if !equal(value, other) {
+			track.Changed++
+		}
+	}
+	for frame := range after {
+		if _, ok := before[frame]; !ok {
+			track.Added++
+		}
+	}
+	return track
+}
+
+// frameIterator はキーフレームを順に列挙できる一覧を表す。
+type frameIterator[T any] interface {
+	ForEach(fn func(frame motion.Frame, value T) bool)
+}
+
+// collectFrames はキーフレームをフレーム番号ごとの対応にする。
+func collectFrames[T any](frames frameIterator[T]) map[motion.Frame]T {
+	out := make(map[motion.Frame]T)
+	frames.ForEach(func(frame motion.Frame, value T) bool {
+		out[frame] = value
+		return true
+	})
+	return out
+}
+
+// boneTrackNames はボーンのトラック名を返す。
+func boneTrackNames(motionData *motion.VmdMotion) []string {
+	if motionData.BoneFrames == nil {
+		return nil
+	}
+	return motionData.BoneFrames.Names()
+}
+
+// morphTrackNames はモーフのトラック名を返す。
+func morphTrackNames(motionData *motion.VmdMotion) []string {
+	if motionData.MorphFrames == nil {
+		return nil
+	}
+	return motionData.MorphFrames.Names()
+}
+
+// unionNames は2つの名前一覧を重複なく名前順に結合する。
+func unionNames(a []string, b []string) []string {
+	set := make(map[string]struct{}, len(a)+len(b))
+	for _, name := range a {
+		set[name] = struct{}{}
+	}
+	for _, name := range b {
+		set[name] = struct{}{}
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// boneFrameMap はボーンのトラックのキーフレームを返す。
+func boneFrameMap(motionData *motion.VmdMotion, name string) map[motion.Frame]*motion.BoneFrame {
+	if motionData.BoneFrames == nil || !motionData.BoneFrames.Contains(name) {
+		return nil
+	}
+	frames := motionData.BoneFrames.Get(name)
+	if frames == nil {
+		return nil
+	}
+	return collectFrames[*motion.BoneFrame](frames)
+}
+
+// morphFrameMap はモーフのトラックのキーフレームを返す。
+func morphFrameMap(motionData *motion.VmdMotion, name string) map[motion.Frame]*motion.MorphFrame {
+	if motionData.MorphFrames == nil || !motionData.MorphFrames.Contains(name) {
+		return nil
+	}
+	frames := motionData.MorphFrames.Get(name)
+	if frames == nil {
+		return nil
+	}
+	return collectFrames[*motion.MorphFrame](frames)
+}
+
+// ikFrameMap はIKフレームを返す。
+func ikFrameMap(motionData *motion.VmdMotion) map[motion.Frame]*motion.IkFrame {
+	if motionData.IkFrames == nil {
+		return nil
+	}
+	return collectFrames[*motion.IkFrame](motionData.IkFrames)
+}
+
+// cameraFrameMap はカメラフレームを返す。
+func cameraFrameMap(motionData *motion.VmdMotion) map[motion.Frame]*motion.CameraFrame {
+	if motionData.CameraFrames == nil {
+		return nil
+	}
+	return collectFrames[*motion.CameraFrame](motionData.CameraFrames)
+}
+
+// lightFrameMap は照明フレームを返す。
+func lightFrameMap(motionData *motion.VmdMotion) map[motion.Frame]*motion.LightFrame {
+	if motionData.LightFrames == nil {
+		return nil
+	}
+	return collectFrames[*motion.LightFrame](motionData.LightFrames)
+}
+
+// shadowFrameMap はセルフ影フレームを返す。
+func shadowFrameMap(motionData *motion.VmdMotion) map[motion.Frame]*motion.ShadowFrame {
+	if motionData.ShadowFrames == nil {
+		return nil
+	}
+	return collectFrames[*motion.ShadowFrame](motionData.ShadowFrames)
+}
+
+// equalBoneFrame はボーンキーフレームの移動・回転・補間曲線が同じか判定する。補間曲線の nil は既定値とみなす。
+func equalBoneFrame(a *motion.BoneFrame, b *motion.BoneFrame) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if !equalVec3(a.Position, b.Position) || !equalQuaternion(a.Rotation, b.Rotation) {
+		return false
+	}
+	curvesA, curvesB := a.Curves, b.Curves
+	if curvesA == nil {
+		curvesA = motion.NewBoneCurves()
+	}
+	if curvesB == nil {
+		curvesB = motion.NewBoneCurves()
+	}
+	return equalCurve(curvesA.TranslateX, curvesB.TranslateX) &&
+		equalCurve(curvesA.TranslateY, curvesB.TranslateY) &&
+		equalCurve(curvesA.TranslateZ, curvesB.TranslateZ) &&
+		equalCurve(curvesA.Rotate, curvesB.Rotate)
+}
+
+// equalMorphFrame はモーフキーフレームの値が同じか判定する。
+func equalMorphFrame(a *motion.MorphFrame, b *motion.MorphFrame) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return math.Abs(a.Ratio-b.Ratio) <= diffTolerance
+}
+
+// equalIkFrame はIKフレームの表示とIKのON/OFFが同じか判定する。
+func equalIkFrame(a *motion.IkFrame, b *motion.IkFrame) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Visible != b.Visible || len(a.IkList) != len(b.IkList) {
+		return false
+	}
+	enabled := make(map[string]bool, len(a.IkList))
+	for _, ik := range a.IkList {
+		if ik != nil {
+			enabled[ik.BoneName] = ik.Enabled
+		}
+	}
+	for _, ik := range b.IkList {
+		if ik == nil {
+			continue
+		}
+		value, ok := enabled[ik.BoneName]
+		if !ok || value != ik.Enabled {
+			return false
+		}
+	}
+	return true
+}
+
+// equalCameraFrame はカメラキーフレームの値が同じか判定する。
+func equalCameraFrame(a *motion.CameraFrame, b *motion.CameraFrame) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if !equalVec3(a.Position, b.Position) || !equalVec3(a.Degrees, b.Degrees) ||
+		math.Abs(a.Distance-b.Distance) > diffTolerance ||
+		a.ViewOfAngle != b.ViewOfAngle || a.IsPerspectiveOff != b.IsPerspectiveOff {
+		return false
+	}
+	curvesA, curvesB := a.Curves, b.Curves
+	if curvesA == nil {
+		curvesA = motion.NewCameraCurves()
+	}
+	if curvesB == nil {
+		curvesB = motion.NewCameraCurves()
+	}
+	return equalCurve(curvesA.TranslateX, curvesB.TranslateX) &&
+		equalCurve(curvesA.TranslateY, curvesB.TranslateY) &&
+		equalCurve(curvesA.TranslateZ, curvesB.TranslateZ) &&
+		equalCurve(curvesA.Rotate, curvesB.Rotate) &&
+		equalCurve(curvesA.Distance, curvesB.Distance) &&
+		equalCurve(curvesA.ViewOfAngle, curvesB.ViewOfAngle)
+}
+
+// equalLightFrame は照明キーフレームの値が同じか判定する。
+func equalLightFrame(a *motion.LightFrame, b *motion.LightFrame) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return equalVec3(a.Position, b.Position) && equalVec3(a.Color, b.Color)
+}
+
+// equalShadowFrame はセルフ影キーフレームの値が同じか判定する。
+func equalShadowFrame(a *motion.ShadowFrame, b *motion.ShadowFrame) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ShadowMode == b.ShadowMode && math.Abs(a.Distance-b.Distance) <= diffTolerance
+}
+
+// equalVec3 はベクトルが誤差の範囲で同じか判定する。nil は零ベクトルとみなす。
+func equalVec3(a *mmath.Vec3, b *mmath.Vec3) bool {
+	va := newIkVec(a)
+	vb := newIkVec(b)
+	return va.sub(vb).length() <= diffTolerance
+}
+
+// equalQuaternion は回転が誤差の範囲で同じか判定する。nil は回転なしとみなし、符号違いの同じ回転も同じとする。
+func equalQuaternion(a *mmath.Quaternion, b *mmath.Quaternion) bool {
+	qa := newIkQuat(a)
+	qb := newIkQuat(b)
+	same := math.Abs(qa.X-qb.X) + math.Abs(qa.Y-qb.Y) + math.Abs(qa.Z-qb.Z) + math.Abs(qa.W-qb.W)
+	flipped := math.Abs(qa.X+qb.X) + math.Abs(qa.Y+qb.Y) + math.Abs(qa.Z+qb.Z) + math.Abs(qa.W+qb.W)
+	return math.Min(same, flipped) <= diffTolerance
+}
+
+// equalCurve は補間曲線が同じか判定する。nil は既定の線形補間とみなす。
+func equalCurve(a *mmath.Curve, b *mmath.Curve) bool {
+	if a == nil {
+		a = mmath.NewCurve()
+	}
+	if b == nil {
+		b = mmath.NewCurve()
+	}
+	return a.Start == b.Start && a.End == b.End
+}
@@ -19,6 +19,10 @@ type MotionViewerUsecaseDeps struct {
 	ModelReader  moutput.IFileReader
 	MotionReader moutput.IFileReader
 	MotionWriter moutput.IFileWriter
+	// PoseWriter はVPDポーズの保存リポジトリ。
+	PoseWriter moutput.IFileWriter
+	// CsvWriter はキーフレームのCSVの保存リポジトリ。
+	CsvWriter moutput.IFileWriter
 }
 
 // MotionViewerUsecase はモーションビューアの入出力処理をまとめたユースケースを表す。
@@ -26,6 +30,8 @@ type MotionViewerUsecase struct {
 	modelReader  moutput.IFileReader
 	motionReader moutput.IFileReader
 	motionWriter moutput.IFileWriter
+	poseWriter   moutput.IFileWriter
+	csvWriter    moutput.IFileWriter
 }
 
 // NewMotionViewerUsecase はモーションビューア用ユースケースを生成する。
@@ -34,6 +40,8 @@ func NewMotionViewerUsecase(deps MotionViewerUsecaseDeps) *MotionViewerUsecase {
 		modelReader:  deps.ModelReader,
 		motionReader: deps.MotionReader,
 		motionWriter: deps.MotionWriter,
+		poseWriter:   deps.PoseWriter,
+		csvWriter:    deps.CsvWriter,
 	}
 }
 
@@ -51,9 +59,10 @@ func (uc *MotionViewerUsecase) LoadModel(rep moutput.IFileReader, path string) (
 }
 
 // LoadMotion はモーションを読み込み、最大フレーム情報を返す。
+// rep が読み込めない形式（CSVなど）の場合はユースケースの読み込みリポジトリを使う。
 func (uc *MotionViewerUsecase) LoadMotion(rep moutput.IFileReader, path string) (*MotionLoadResult, error) {
 	repo := rep
-	if repo == nil {
+	if repo == nil || (uc.motionReader != nil && !repo.CanLoad(path) && uc.motionReader.CanLoad(path)) {
 		repo = uc.motionReader
 	}
 	return usecase.LoadMotionWithMeta(repo, path)
@@ -72,6 +81,81 @@ func (uc *MotionViewerUsecase) SaveSafeMotion(request SafeMotionSaveRequest) (*S
 	return SaveSafeMotion(request)
 }
 
+// SaveFittedMotion はモデルで再生できるトラックだけを残したモーションを保存する。
+func (uc *MotionViewerUsecase) SaveFittedMotion(request FittedMotionSaveRequest) (*FittedMotionSaveResult, error) {
+	if request.Writer == nil {
+		request.Writer = uc.motionWriter
+	}
+	return SaveFittedMotion(request)
+}
+
+// SaveRenamedMotion はトラック名をモデルの名前に変換したモーションを保存する。
+func (uc *MotionViewerUsecase) SaveRenamedMotion(request RenameMotionSaveRequest) (*RenameMotionSaveResult, error) {
+	if request.Writer == nil {
+		request.Writer = uc.motionWriter
+	}
+	return SaveRenamedMotion(request)
+}
+
+// SaveRangeMotion は区間を取り出して0フレームに詰めたモーションを保存する。
+func (uc *MotionViewerUsecase) SaveRangeMotion(request RangeMotionSaveRequest) (*RangeMotionSaveResult, error) {
+	if request.Writer == nil {
+		request.Writer = uc.motionWriter
+	}
+	return SaveRangeMotion(request)
+}
+
+// SaveRetimedMotion はキーの時間を伸縮または打ち直したモーションを保存する。
+func (uc *MotionViewerUsecase) SaveRetimedMotion(request RetimeMotionSaveRequest) (*RetimeMotionSaveResult, error) {
+	if request.Writer == nil {
+		request.Writer = uc.motionWriter
+	}
+	return SaveRetimedMotion(request)
+}
+
+// SaveMirroredMotion は左右反転したモーションを保存する。
+func (uc *MotionViewerUsecase) SaveMirroredMotion(request MirrorMotionSaveRequest) (*MirrorMotionSaveResult, error) {
+	if request.Writer == nil {
+		request.Writer = uc.motionWriter
+	}
+	return SaveMirroredMotion(request)
+}
+
+// SaveReducedMotion はキーを間引いたモーションを保存する。
+func (uc *MotionViewerUsecase) SaveReducedMotion(request ReduceMotionSaveRequest) (*ReduceMotionSaveResult, error) {
+	if request.Writer == nil {
+		request.Writer = uc.motionWriter
+	}
+	return SaveReducedMotion(request)
+}
+
+// ExportPose はモーションの指定フレームをVPDポーズとして保存する。
+func (uc *MotionViewerUsecase) ExportPose(request PoseExportRequest) (*PoseExportResult, error) {
+	if request.Writer == nil {
+		request.Writer = uc.poseWriter
+	}
+	return ExportPose(request)
+}
+
+// ImportPoses はVPDポーズを読み込み、1つのモーションにして保存する。
+func (uc *MotionViewerUsecase) ImportPoses(request PoseImportRequest) (*PoseImportResult, error) {
+	if request.Reader == nil {
+		request.Reader = uc.motionReader
+	}
+	if request.Writer == nil {
+		request.Writer = uc.motionWriter
+	}
+	return ImportPoses(request)
+}
+
+// ExportCsv はモーションの全キーフレームをCSVとして保存する。
+func (uc *MotionViewerUsecase) ExportCsv(request CsvExportRequest) (*CsvExportResult, error) {
+	if request.Writer == nil {
+		request.Writer = uc.csvWriter
+	}
+	return ExportCsv(request)
+}
+
 // ExtractModelData は読み込み結果からモデルを取り出す。
 func ExtractModelData(result *ModelLoadResult) *model.PmxModel {
 	if result == nil {
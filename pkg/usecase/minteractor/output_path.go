@@ -0,0 +1,176 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CollisionPolicy は保存先に同名のファイルが既にある場合の扱いを表す。
+type CollisionPolicy string
+
+// 保存先の重複時の扱い一覧。
+const (
+	// CollisionOverwrite は既存のファイルを上書きする。
+	CollisionOverwrite CollisionPolicy = "overwrite"
+	// CollisionIncrement はファイル名の末尾に連番を付けて空いている名前で保存する。
+	CollisionIncrement CollisionPolicy = "increment"
+	// CollisionSkip は保存しない。
+	CollisionSkip CollisionPolicy = "skip"
+	// CollisionAsk は上書きしてよいか確認し、拒否された場合は保存しない。
+	CollisionAsk CollisionPolicy = "ask"
+)
+
+// CollisionPolicies は画面や設定で扱う重複時の扱いの表示順。
+var CollisionPolicies = []CollisionPolicy{CollisionOverwrite, CollisionIncrement, CollisionSkip, CollisionAsk}
+
+// DefaultOutputTemplate は従来と同じ「元の名前_操作名」のファイル名テンプレート。
+const DefaultOutputTemplate = "{name}_{op}"
+
+// 保存操作名の一覧。ファイル名テンプレートの {op} に入る。
+const (
+	OutputOpSafe    = "safe"
+	OutputOpFit     = "fit"
+	OutputOpRenamed = "renamed"
+	OutputOpRange   = "range"
+	OutputOpMirror  = "mirror"
+	OutputOpReduce  = "reduce"
+	OutputOpPose    = "pose"
+	OutputOpVpd     = "vpd"
+	OutputOpCsv     = "csv"
+)
+
+// outputIncrementLimit は連番を付けて探す上限。
+const outputIncrementLimit = 9999
+
+// OutputPathOptions は保存先パスの決め方を表す。
+type OutputPathOptions struct {
+	// Template はファイル名のテンプレート。空の場合は DefaultOutputTemplate とする。
+	// {name} 元のファイル名, {model} モデル名, {date} 日付(YYYYMMDD), {time} 時刻(hhmmss), {op} 保存操作名 を置き換える。
+	Template string
+	// Dir は保存先フォルダ。空の場合は元のファイルと同じフォルダとする。
+	Dir string
+	// Extension は保存先の拡張子（「.」を含む）。空の場合は元のファイルと同じとする。
+	Extension string
+	// ModelName は {model} に入れるモデル名。
+	ModelName string
+	// Collision は保存先に同名のファイルがある場合の扱い。空の場合は CollisionOverwrite とする。
+	Collision CollisionPolicy
+	// ConfirmOverwrite は CollisionAsk のときに上書きしてよいか確認する。nil の場合は保存しない。
+	ConfirmOverwrite func(path string) bool
+	// Now は {date} と {time} に使う時刻。ゼロ値の場合は現在時刻とする。
+	Now time.Time
+	// Exists は保存先が存在するか判定する。nil の場合はファイルシステムを参照する。
+	Exists func(path string) bool
+}
+
+// ResolveOutputPath は元のファイルのパスと保存操作名から保存先のパスを決める。
+// 重複時の扱いにより保存しない場合は、重複したパスと true を返す。
+func ResolveOutputPath(basePath string, op string, options OutputPathOptions) (string, bool, error) {
+	if basePath == "" {
+		return "", false, nil
+	}
+	path, err := buildTemplatePath(basePath, op, options)
+	if err != nil {
+		return "", false, err
+	}
+	exists := options.Exists
+	if exists == nil {
+		exists = fileExists
+	}
+	if !exists(path) {
+		return path, false, nil
+	}
+	switch options.Collision {
+	case "", CollisionOverwrite:
+		return path, false, nil
+	case CollisionIncrement:
+		ext := filepath.Ext(path)
+		stem := strings.TrimSuffix(path, ext)
+		for i := 2; i <= outputIncrementLimit; i++ {
+			candidate := stem + "_" + strconv.Itoa(i) + ext
+			if !exists(candidate) {
+				return candidate, false, nil
+			}
+		}
+		return path, false, fmt.Errorf("空いている連番のファイル名が見つかりません: %s", path)
+	case CollisionSkip:
+		return path, true, nil
+	case CollisionAsk:
+		if options.ConfirmOverwrite != nil && options.ConfirmOverwrite(path) {
+			return path, false, nil
+		}
+		return path, true, nil
+	default:
+		return "", false, fmt.Errorf("未対応の保存先の重複時の扱いです: %s", options.Collision)
+	}
+}
+
+// buildTemplatePath はテンプレートを展開して保存先のパスを作る。拡張子は元のファイルに合わせる。
+func buildTemplatePath(basePath string, op string, options OutputPathOptions) (string, error) {
+	dir, base := filepath.Split(basePath)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+	if options.Extension != "" {
+		ext = options.Extension
+	} else if strings.EqualFold(ext, csvExtension) || strings.EqualFold(ext, poseExtension) {
+		// CSVやVPDから読み込んだモーションはVMDとして保存するため、元の拡張子に合わせない。
+		ext = motionExtension
+	}
+	if ext == "" {
+		ext = motionExtension
+	}
+	if options.Dir != "" {
+		dir = options.Dir
+	}
+	template := strings.TrimSpace(options.Template)
+	if template == "" {
+		template = DefaultOutputTemplate
+	}
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	replacer := strings.NewReplacer(
+		"{name}", name,
+		"{model}", sanitizeFileName(options.ModelName),
+		"{date}", now.Format("20060102"),
+		"{time}", now.Format("150405"),
+		"{op}", op,
+	)
+	fileName := strings.TrimSpace(replacer.Replace(template))
+	if strings.ContainsAny(fileName, `/\`) {
+		return "", fmt.Errorf("ファイル名テンプレートにフォルダの区切り文字は使えません: %s", options.Template)
+	}
+	if fileName == "" {
+		fileName = op
+	}
+	if strings.EqualFold(filepath.Ext(fileName), ext) {
+		fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	}
+	return filepath.Join(dir, sanitizeFileName(fileName)+ext), nil
+}
+
+// sanitizeFileName はファイル名に使えない文字を「_」に置き換える。
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '<', '>', ':', '"', '/', '\\', '|', '?', '*':
+			return '_'
+		}
+		if r < 0x20 {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// fileExists はパスにファイルやフォルダが存在するか判定する。
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
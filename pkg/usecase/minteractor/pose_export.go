@@ -0,0 +1,116 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// poseExtension はポーズファイルの拡張子。
+const poseExtension = ".vpd"
+
+// PoseExportRequest はVPDポーズ書き出しの入力を表す。
+type PoseExportRequest struct {
+	Motion       *motion.VmdMotion
+	FallbackPath string
+	// Writer はポーズの保存リポジトリ。0フレームにキーを持つモーションを受け取る。
+	Writer      moutput.IFileWriter
+	SaveOptions moutput.SaveOptions
+	// Frame は書き出すフレーム。キーの間は補間した値を使う。
+	Frame motion.Frame
+	// Output は保存先のファイル名テンプレート・フォルダ・重複時の扱い。
+	Output OutputPathOptions
+}
+
+// PoseExportResult はVPDポーズ書き出しの結果を表す。
+type PoseExportResult struct {
+	BasePath string
+	PosePath string
+	// Bones/Morphs は書き出したボーン/モーフの数。
+	Bones  int
+	Morphs int
+	// OutputSkipped は保存先の重複時の扱いにより保存しなかったかを表す。
+	OutputSkipped bool
+}
+
+// BuildPoseMotion はモーションの指定フレームの全ボーン/モーフを補間して取り出し、0フレームのキーにしたモーションを作る。
+func BuildPoseMotion(source *motion.VmdMotion, frame motion.Frame) *motion.VmdMotion {
+	if source == nil {
+		return nil
+	}
+	pose := motion.NewVmdMotion("")
+	pose.SetName(source.Name())
+	pose.BoneFrames = motion.NewBoneFrames()
+	pose.MorphFrames = motion.NewMorphFrames()
+	if source.BoneFrames != nil {
+		for _, name := range source.BoneFrames.Names() {
+			frames := source.BoneFrames.Get(name)
+			if frames == nil || frames.Len() == 0 {
+				continue
+			}
+			nameFrames := motion.NewBoneNameFrames(name)
+			nameFrames.Append(cloneBoneFrame(frames.Get(frame), 0))
+			pose.BoneFrames.Update(nameFrames)
+		}
+	}
+	if source.MorphFrames != nil {
+		for _, name := range source.MorphFrames.Names() {
+			frames := source.MorphFrames.Get(name)
+			if frames == nil || frames.Len() == 0 {
+				continue
+			}
+			nameFrames := motion.NewMorphNameFrames(name)
+			nameFrames.Append(cloneMorphFrame(frames.Get(frame), 0))
+			pose.MorphFrames.Update(nameFrames)
+		}
+	}
+	return pose
+}
+
+// ExportPose はモーションの指定フレームをVPDポーズとして保存する。
+// ファイル名の {op} は「pose」とフレーム番号をつなげたものになる。
+func ExportPose(request PoseExportRequest) (*PoseExportResult, error) {
+	result := &PoseExportResult{}
+	if request.Motion == nil {
+		return result, nil
+	}
+	basePath := request.Motion.Path()
+	if basePath == "" {
+		basePath = request.FallbackPath
+	}
+	result.BasePath = basePath
+	if basePath == "" {
+		return result, nil
+	}
+	if request.Writer == nil {
+		return result, fmt.Errorf("保存リポジトリがありません")
+	}
+	if request.Frame < 0 {
+		return result, fmt.Errorf("書き出すフレームが不正です: %v", request.Frame)
+	}
+
+	pose := BuildPoseMotion(request.Motion, request.Frame)
+	if pose == nil {
+		return result, nil
+	}
+	result.Bones = len(pose.BoneFrames.Names())
+	result.Morphs = len(pose.MorphFrames.Names())
+
+	output := request.Output
+	output.Extension = poseExtension
+	posePath, skipped, err := ResolveOutputPath(basePath, fmt.Sprintf("%s%v", OutputOpPose, request.Frame), output)
+	if err != nil {
+		return result, err
+	}
+	result.PosePath = posePath
+	result.OutputSkipped = skipped
+	if posePath == "" || skipped {
+		return result, nil
+	}
+	if err := request.Writer.Save(posePath, pose, request.SaveOptions); err != nil {
+		return result, err
+	}
+	return result, nil
+}
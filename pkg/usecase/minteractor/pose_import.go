@@ -0,0 +1,330 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mlib_go/pkg/usecase"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// motionExtension はVPDから変換したモーションの拡張子。
+const motionExtension = ".vmd"
+
+// InterpolationPreset はキーの間の補間曲線の種類を表す。
+type InterpolationPreset string
+
+// 補間曲線の種類一覧。
+const (
+	InterpolationLinear    InterpolationPreset = "linear"
+	InterpolationEaseInOut InterpolationPreset = "easeinout"
+	InterpolationEaseIn    InterpolationPreset = "easein"
+	InterpolationEaseOut   InterpolationPreset = "easeout"
+)
+
+// InterpolationPresets は画面で扱う補間曲線の種類の表示順。
+var InterpolationPresets = []InterpolationPreset{
+	InterpolationLinear,
+	InterpolationEaseInOut,
+	InterpolationEaseIn,
+	InterpolationEaseOut,
+}
+
+// PoseSequenceOptions はポーズを並べてキーにする設定を表す。
+type PoseSequenceOptions struct {
+	// Spacing はポーズごとのキーの間隔（フレーム）。
+	Spacing motion.Frame
+	// Preset はボーンのキーに設定する補間曲線。
+	Preset InterpolationPreset
+}
+
+// DefaultPoseSequenceOptions はポーズを並べる既定の設定を返す。
+func DefaultPoseSequenceOptions() PoseSequenceOptions {
+	return PoseSequenceOptions{Spacing: 30, Preset: InterpolationLinear}
+}
+
+// PoseImportRequest はVPDポーズからモーションへの変換の入力を表す。
+type PoseImportRequest struct {
+	// PosePaths にはVPDファイルまたはフォルダを並べる順に指定する。
+	// フォルダは直下のVPDファイルをファイル名の自然順（pose2 → pose10）に展開する。
+	PosePaths []string
+	// Reader はポーズの読み込みリポジトリ。
+	Reader      moutput.IFileReader
+	Writer      moutput.IFileWriter
+	SaveOptions moutput.SaveOptions
+	Sequence    PoseSequenceOptions
+	// Output は保存先のファイル名テンプレート・フォルダ・重複時の扱い。
+	Output OutputPathOptions
+}
+
+// PoseImportResult はVPDポーズからモーションへの変換の結果を表す。
+type PoseImportResult struct {
+	// BasePath は保存先の基準にした先頭のポーズのパス。
+	BasePath   string
+	MotionPath string
+	// PosePaths はキーにした順のポーズのパス。
+	PosePaths []string
+	// Bones/Morphs はキーを打ったボーン/モーフの数。
+	Bones  int
+	Morphs int
+	// OutputSkipped は保存先の重複時の扱いにより保存しなかったかを表す。
+	OutputSkipped bool
+}
+
+// BuildPoseSequenceMotion は各ポーズの0フレームの値を Spacing 間隔のキーにしたモーションを作る。
+// ポーズが1つの場合は0フレームだけのモーションになる。
+// あるポーズに含まれないボーン/モーフは、直前のポーズの値（それまでに現れていない場合は初期値）のキーを打つ。
+func BuildPoseSequenceMotion(poses []*motion.VmdMotion, options PoseSequenceOptions) (*motion.VmdMotion, error) {
+	if len(poses) == 0 {
+		return nil, nil
+	}
+	if len(poses) > 1 && options.Spacing <= 0 {
+		return nil, fmt.Errorf("キーの間隔が不正です: %v", options.Spacing)
+	}
+	curve, err := interpolationCurve(options.Preset)
+	if err != nil {
+		return nil, err
+	}
+
+	boneNames := make([]string, 0)
+	morphNames := make([]string, 0)
+	seenBones := make(map[string]struct{})
+	seenMorphs := make(map[string]struct{})
+	for _, pose := range poses {
+		if pose == nil {
+			continue
+		}
+		if pose.BoneFrames != nil {
+			for _, name := range pose.BoneFrames.Names() {
+				if _, ok := seenBones[name]; !ok {
+					seenBones[name] = struct{}{}
+					boneNames = append(boneNames, name)
+				}
+			}
+		}
+		if pose.MorphFrames != nil {
+			for _, name := range pose.MorphFrames.Names() {
+				if _, ok := seenMorphs[name]; !ok {
+					seenMorphs[name] = struct{}{}
+					morphNames = append(morphNames, name)
+				}
+			}
+		}
+	}
+
+	sequence := motion.NewVmdMotion("")
+	if poses[0] != nil {
+		sequence.SetName(poses[0].Name())
+	}
+	sequence.BoneFrames = motion.NewBoneFrames()
+	for _, name := range boneNames {
+		nameFrames := motion.NewBoneNameFrames(name)
+		var previous *motion.BoneFrame
+		for i, pose := range poses {
+			if value := poseBoneFrame(pose, name); value != nil {
+				previous = value
+			}
+			key := cloneBoneFrame(previous, motion.Frame(i)*options.Spacing)
+			key.Curves = motion.NewBoneCurves()
+			key.Curves.TranslateX = curve.Copy()
+			key.Curves.TranslateY = curve.Copy()
+			key.Curves.TranslateZ = curve.Copy()
+			key.Curves.Rotate = curve.Copy()
+			nameFrames.Append(key)
+		}
+		sequence.BoneFrames.Update(nameFrames)
+	}
+	sequence.MorphFrames = motion.NewMorphFrames()
+	for _, name := range morphNames {
+		nameFrames := motion.NewMorphNameFrames(name)
+		var previous *motion.MorphFrame
+		for i, pose := range poses {
+			if value := poseMorphFrame(pose, name); value != nil {
+				previous = value
+			}
+			nameFrames.Append(cloneMorphFrame(previous, motion.Frame(i)*options.Spacing))
+		}
+		sequence.MorphFrames.Update(nameFrames)
+	}
+	return sequence, nil
+}
+
+// ImportPoses はVPDポーズを読み込み、1つのモーションにして保存する。
+// ファイル名の {op} は「vpd」になり、拡張子は .vmd になる。
+func ImportPoses(request PoseImportRequest) (*PoseImportResult, error) {
+	result := &PoseImportResult{}
+	if len(request.PosePaths) == 0 {
+		return result, nil
+	}
+	if request.Reader == nil {
+		return result, fmt.Errorf("読み込みリポジトリがありません")
+	}
+	if request.Writer == nil {
+		return result, fmt.Errorf("保存リポジトリがありません")
+	}
+	posePaths, err := expandPosePaths(request.PosePaths)
+	if err != nil {
+		return result, err
+	}
+	if len(posePaths) == 0 {
+		return result, fmt.Errorf("VPDファイルがありません")
+	}
+	result.BasePath = posePaths[0]
+	result.PosePaths = posePaths
+
+	poses := make([]*motion.VmdMotion, len(posePaths))
+	for i, path := range posePaths {
+		pose, err := usecase.LoadMotion(request.Reader, path)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", path, err)
+		}
+		poses[i] = pose
+	}
+	sequence, err := BuildPoseSequenceMotion(poses, request.Sequence)
+	if err != nil {
+		return result, err
+	}
+	result.Bones = len(sequence.BoneFrames.Names())
+	result.Morphs = len(sequence.MorphFrames.Names())
+
+	output := request.Output
+	output.Extension = motionExtension
+	motionPath, skipped, err := ResolveOutputPath(result.BasePath, OutputOpVpd, output)
+	if err != nil {
+		return result, err
+	}
+	result.MotionPath = motionPath
+	result.OutputSkipped = skipped
+	if motionPath == "" || skipped {
+		return result, nil
+	}
+	if err := request.Writer.Save(motionPath, sequence, request.SaveOptions); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// interpolationCurve は補間曲線の種類からVMDの補間曲線を作る。
+func interpolationCurve(preset InterpolationPreset) (*mmath.Curve, error) {
+	curve := mmath.NewCurve()
+	switch preset {
+	case InterpolationLinear, "":
+	case InterpolationEaseInOut:
+		curve.Start = mmath.Vec2{X: 64, Y: 0}
+		curve.End = mmath.Vec2{X: 64, Y: curveScale}
+	case InterpolationEaseIn:
+		curve.Start = mmath.Vec2{X: 64, Y: 0}
+		curve.End = mmath.Vec2{X: curveScale, Y: curveScale}
+	case InterpolationEaseOut:
+		curve.Start = mmath.Vec2{X: 0, Y: 0}
+		curve.End = mmath.Vec2{X: 64, Y: curveScale}
+	default:
+		return nil, fmt.Errorf("補間曲線の種類が不正です: %s", preset)
+	}
+	return curve, nil
+}
+
+// poseBoneFrame はポーズの0フレームのボーンの値を返す。ボーンがない場合は nil。
+func poseBoneFrame(pose *motion.VmdMotion, name string) *motion.BoneFrame {
+	if pose == nil || pose.BoneFrames == nil || !pose.BoneFrames.Contains(name) {
+		return nil
+	}
+	return pose.BoneFrames.Get(name).Get(0)
+}
+
+// poseMorphFrame はポーズの0フレームのモーフの値を返す。モーフがない場合は nil。
+func poseMorphFrame(pose *motion.VmdMotion, name string) *motion.MorphFrame {
+	if pose == nil || pose.MorphFrames == nil || !pose.MorphFrames.Contains(name) {
+		return nil
+	}
+	return pose.MorphFrames.Get(name).Get(0)
+}
+
+// SortPathsNatural はパスを自然順に並べ替える。数字の並びは数値として比べるため、pose2 は pose10 より前になる。
+func SortPathsNatural(paths []string) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		return naturalLess(paths[i], paths[j])
+	})
+}
+
+// naturalLess は数字の並びを数値として、それ以外を大文字小文字を区別せず文字ごとに比べる。
+// 同じ順位になる場合は元の文字列で比べる。
+func naturalLess(a string, b string) bool {
+	left := []rune(strings.ToLower(a))
+	right := []rune(strings.ToLower(b))
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		if isAsciiDigit(left[i]) && isAsciiDigit(right[j]) {
+			start := i
+			for i < len(left) && isAsciiDigit(left[i]) {
+				i++
+			}
+			leftNumber := strings.TrimLeft(string(left[start:i]), "0")
+			start = j
+			for j < len(right) && isAsciiDigit(right[j]) {
+				j++
+			}
+			rightNumber := strings.TrimLeft(string(right[start:j]), "0")
+			if len(leftNumber) != len(rightNumber) {
+				return len(leftNumber) < len(rightNumber)
+			}
+			if leftNumber != rightNumber {
+				return leftNumber < rightNumber
+			}
+			continue
+		}
+		if left[i] != right[j] {
+			return left[i] < right[j]
+		}
+		i++
+		j++
+	}
+	if len(left)-i != len(right)-j {
+		return len(left)-i < len(right)-j
+	}
+	return a < b
+}
+
+// isAsciiDigit は半角数字か判定する。
+func isAsciiDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// expandPosePaths はフォルダを直下のVPDファイルへ自然順に展開する。ファイルは指定順のまま並べる。
+// VPD以外のファイルを指定した場合はエラーにする。
+func expandPosePaths(paths []string) ([]string, error) {
+	out := make([]string, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if !strings.EqualFold(filepath.Ext(path), poseExtension) {
+				return nil, fmt.Errorf("VPDファイルではありません: %s", path)
+			}
+			out = append(out, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		found := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), poseExtension) {
+				continue
+			}
+			found = append(found, filepath.Join(path, entry.Name()))
+		}
+		SortPathsNatural(found)
+		out = append(out, found...)
+	}
+	return out, nil
+}
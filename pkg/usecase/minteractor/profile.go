@@ -0,0 +1,196 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/model"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+)
+
+// BoneEffect はボーン欠落時に見た目へ出る影響を表す。
+// 組み込みプロファイルの値は日本語の説明文で、メッセージキーとしても使う。
+type BoneEffect string
+
+// 組み込みプロファイルで使う欠落時の影響。
+const (
+	BoneEffectStandard    BoneEffect = "標準ボーンのキーが失われ、その部位が動きません"
+	BoneEffectRoot        BoneEffect = "全ての親のキーが失われ、モーション全体の位置や向きがずれます"
+	BoneEffectGroove      BoneEffect = "グルーブのキーが失われ、上下の揺れが小さくなります"
+	BoneEffectWaist       BoneEffect = "腰のキーが失われ、腰のひねりが再現されません"
+	BoneEffectUpper2      BoneEffect = "上半身2のキーが失われ、胸から上の反りやひねりが小さくなります"
+	BoneEffectShoulderP   BoneEffect = "肩Pのキーが失われ、肩のすくめや上下が再現されません"
+	BoneEffectTwist       BoneEffect = "捩りのキーが失われ、腕や手首のひねりが再現されません"
+	BoneEffectThumb0      BoneEffect = "親指０のキーが失われ、親指の付け根が動きません"
+	BoneEffectLegIkParent BoneEffect = "足IK親のキーが失われ、足の接地位置がずれます"
+	BoneEffectWaistCancel BoneEffect = "腰キャンセルのキーが失われ、腰の動きが足に伝わります"
+	BoneEffectLegD        BoneEffect = "足Dボーンのキーが失われ、足の付与回転が再現されません"
+	BoneEffectToeEx       BoneEffect = "足先EXのキーが失われ、つま先の曲げが再現されません"
+	BoneEffectViewCenter  BoneEffect = "操作中心のキーが失われますが、見た目には影響しません"
+)
+
+// builtinBoneEffects は組み込みの影響一覧を表す。
+var builtinBoneEffects = map[BoneEffect]struct{}{
+	BoneEffectStandard:    {},
+	BoneEffectRoot:        {},
+	BoneEffectGroove:      {},
+	BoneEffectWaist:       {},
+	BoneEffectUpper2:      {},
+	BoneEffectShoulderP:   {},
+	BoneEffectTwist:       {},
+	BoneEffectThumb0:      {},
+	BoneEffectLegIkParent: {},
+	BoneEffectWaistCancel: {},
+	BoneEffectLegD:        {},
+	BoneEffectToeEx:       {},
+	BoneEffectViewCenter:  {},
+}
+
+// BoneProfile はボーン構成の監査基準を表す。
+type BoneProfile struct {
+	Name  string        `json:"name"`
+	Bones []ProfileBone `json:"bones"`
+}
+
+// ProfileBone は監査対象のボーンと欠落時の影響を表す。
+type ProfileBone struct {
+	Name   string     `json:"name"`
+	Effect BoneEffect `json:"effect"`
+}
+
+// ProfileAuditEntry はモーションが動かしているがモデルにないプロファイルボーンを表す。
+type ProfileAuditEntry struct {
+	TrackEntry
+	Profile string
+	Effect  BoneEffect
+}
+
+// IsBuiltinBoneEffect は組み込みの影響（メッセージキー）か判定する。
+func IsBuiltinBoneEffect(effect BoneEffect) bool {
+	_, ok := builtinBoneEffects[effect]
+	return ok
+}
+
+// StandardBoneProfile はMMD標準ボーンのプロファイルを返す。
+func StandardBoneProfile() BoneProfile {
+	profile := BoneProfile{Name: "MMD標準"}
+	profile.add(BoneEffectStandard, "センター", "上半身", "首", "頭", "下半身", "両目")
+	profile.addSided(BoneEffectStandard,
+		"目", "肩", "腕", "ひじ", "手首",
+		"親指１", "親指２", "人指１", "人指２", "人指３",
+		"中指１", "中指２", "中指３", "薬指１", "薬指２", "薬指３",
+		"小指１", "小指２", "小指３",
+		"足", "ひざ", "足首", "足ＩＫ", "つま先ＩＫ",
+	)
+	return profile
+}
+
+// SemiStandardBoneProfile は準標準ボーンのプロファイルを返す。
+func SemiStandardBoneProfile() BoneProfile {
+	profile := BoneProfile{Name: "準標準"}
+	profile.add(BoneEffectRoot, "全ての親")
+	profile.add(BoneEffectGroove, "グルーブ")
+	profile.add(BoneEffectWaist, "腰")
+	profile.add(BoneEffectUpper2, "上半身2")
+	profile.add(BoneEffectViewCenter, "操作中心")
+	profile.addSided(BoneEffectShoulderP, "肩P")
+	profile.addSided(BoneEffectTwist, "腕捩", "手捩")
+	profile.addSided(BoneEffectThumb0, "親指０")
+	profile.addSided(BoneEffectLegIkParent, "足IK親")
+	profile.addSided(BoneEffectLegD, "足D", "ひざD", "足首D")
+	profile.addSided(BoneEffectToeEx, "足先EX")
+	profile.add(BoneEffectWaistCancel, "腰キャンセル左", "腰キャンセル右")
+	return profile
+}
+
+// BuiltinBoneProfiles は組み込みのプロファイル一覧を返す。
+func BuiltinBoneProfiles() []BoneProfile {
+	return []BoneProfile{StandardBoneProfile(), SemiStandardBoneProfile()}
+}
+
+// LoadBoneProfiles はJSONファイルからプロファイルを読み込む。
+// 単一のプロファイルとプロファイル配列のどちらの形式にも対応する。
+func LoadBoneProfiles(path string) ([]BoneProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(data) > 0 && data[0] == '[' {
+		profiles := make([]BoneProfile, 0)
+		if err := json.Unmarshal(data, &profiles); err != nil {
+			return nil, err
+		}
+		return profiles, nil
+	}
+	profile := BoneProfile{}
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, err
+	}
+	if profile.Name == "" {
+		return nil, fmt.Errorf("ボーンプロファイル名がありません: %s", path)
+	}
+	return []BoneProfile{profile}, nil
+}
+
+// AuditBoneProfiles はモーションが動かしているがモデルにないプロファイルボーンを列挙する。
+func AuditBoneProfiles(modelData *model.PmxModel, motionData *motion.VmdMotion, profiles []BoneProfile) ([]ProfileAuditEntry, error) {
+	if motionData == nil || motionData.BoneFrames == nil {
+		return nil, nil
+	}
+	active := make(map[string]struct{})
+	for _, name := range collectActiveBoneNames(motionData) {
+		active[name] = struct{}{}
+	}
+
+	out := make([]ProfileAuditEntry, 0)
+	seen := make(map[string]struct{})
+	for _, profile := range profiles {
+		for _, profileBone := range profile.Bones {
+			if _, ok := active[profileBone.Name]; !ok {
+				continue
+			}
+			if _, ok := seen[profileBone.Name]; ok {
+				// 複数プロファイルにある場合は先に登録したプロファイルで報告する。
+				continue
+			}
+			_, exists, err := resolveBone(modelData, profileBone.Name)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				continue
+			}
+			seen[profileBone.Name] = struct{}{}
+			out = append(out, ProfileAuditEntry{
+				TrackEntry: TrackEntry{
+					Name:       profileBone.Name,
+					TrackStats: collectBoneStats(motionData.BoneFrames.Get(profileBone.Name)),
+				},
+				Profile: profile.Name,
+				Effect:  profileBone.Effect,
+			})
+		}
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// add はボーンを影響付きで追加する。
+func (p *BoneProfile) add(effect BoneEffect, names ...string) {
+	for _, name := range names {
+		p.Bones = append(p.Bones, ProfileBone{Name: name, Effect: effect})
+	}
+}
+
+// addSided は左右のボーンを影響付きで追加する。
+func (p *BoneProfile) addSided(effect BoneEffect, names ...string) {
+	for _, name := range names {
+		p.add(effect, "左"+name, "右"+name)
+	}
+}
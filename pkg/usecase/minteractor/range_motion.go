@@ -0,0 +1,225 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// RangeMotionSaveRequest は区間書き出しモーション保存の入力を表す。
+type RangeMotionSaveRequest struct {
+	Motion       *motion.VmdMotion
+	FallbackPath string
+	Writer       moutput.IFileWriter
+	SaveOptions  moutput.SaveOptions
+	// Start/End は書き出す区間（両端を含む）。
+	Start motion.Frame
+	End   motion.Frame
+	// Output は保存先のファイル名テンプレート・フォルダ・重複時の扱い。
+	Output OutputPathOptions
+}
+
+// RangeMotionSaveResult は区間書き出しモーション保存の結果を表す。
+type RangeMotionSaveResult struct {
+	BasePath  string
+	RangePath string
+	// OutputSkipped は保存先の重複時の扱いにより保存しなかったかを表す。
+	OutputSkipped bool
+}
+
+// BuildRangeMotion はモーションの [start, end] の区間を取り出し、start が0フレームになるよう詰めたモーションを複製する。
+// 各トラックの両端には補間した値のキーを追加し、区間にかかる補間曲線は分割して元と同じ動きになるようにする。
+func BuildRangeMotion(source *motion.VmdMotion, start motion.Frame, end motion.Frame) (*motion.VmdMotion, error) {
+	if source == nil {
+		return nil, nil
+	}
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("書き出す区間が不正です: %v-%v", start, end)
+	}
+	copied, err := source.Copy()
+	if err != nil {
+		return nil, err
+	}
+
+	copied.BoneFrames = motion.NewBoneFrames()
+	if source.BoneFrames != nil {
+		for _, name := range source.BoneFrames.Names() {
+			frames := source.BoneFrames.Get(name)
+			if frames == nil || frames.Len() == 0 {
+				continue
+			}
+			extracted := extractRangeFrames[*motion.BoneFrame](frames, start, end, boneFrameAdapter(frames))
+			nameFrames := motion.NewBoneNameFrames(name)
+			for _, value := range extracted {
+				nameFrames.Append(value)
+			}
+			copied.BoneFrames.Update(nameFrames)
+		}
+	}
+
+	copied.MorphFrames = motion.NewMorphFrames()
+	if source.MorphFrames != nil {
+		for _, name := range source.MorphFrames.Names() {
+			frames := source.MorphFrames.Get(name)
+			if frames == nil || frames.Len() == 0 {
+				continue
+			}
+			extracted := extractRangeFrames[*motion.MorphFrame](frames, start, end, morphFrameAdapter(frames))
+			nameFrames := motion.NewMorphNameFrames(name)
+			for _, value := range extracted {
+				nameFrames.Append(value)
+			}
+			copied.MorphFrames.Update(nameFrames)
+		}
+	}
+
+	copied.CameraFrames = motion.NewCameraFrames()
+	if source.CameraFrames != nil && source.CameraFrames.Len() > 0 {
+		extracted := extractRangeFrames[*motion.CameraFrame](source.CameraFrames, start, end, cameraFrameAdapter(source.CameraFrames))
+		for _, value := range extracted {
+			copied.CameraFrames.Append(value)
+		}
+	}
+
+	copied.LightFrames = motion.NewLightFrames()
+	if source.LightFrames != nil && source.LightFrames.Len() > 0 {
+		extracted := extractRangeFrames[*motion.LightFrame](source.LightFrames, start, end, lightFrameAdapter(source.LightFrames))
+		for _, value := range extracted {
+			copied.LightFrames.Append(value)
+		}
+	}
+
+	copied.ShadowFrames = motion.NewShadowFrames()
+	if source.ShadowFrames != nil && source.ShadowFrames.Len() > 0 {
+		extracted := extractRangeFrames[*motion.ShadowFrame](source.ShadowFrames, start, end, shadowFrameAdapter(source.ShadowFrames))
+		for _, value := range extracted {
+			copied.ShadowFrames.Append(value)
+		}
+	}
+
+	copied.IkFrames = motion.NewIkFrames()
+	if source.IkFrames != nil && source.IkFrames.Len() > 0 {
+		extracted := extractRangeFrames[*motion.IkFrame](source.IkFrames, start, end, ikFrameAdapter())
+		for _, value := range extracted {
+			copied.IkFrames.Append(value)
+		}
+	}
+	return &copied, nil
+}
+
+// SaveRangeMotion は区間を取り出して0フレームに詰めたモーションを保存する。
+func SaveRangeMotion(request RangeMotionSaveRequest) (*RangeMotionSaveResult, error) {
+	result := &RangeMotionSaveResult{}
+	if request.Motion == nil {
+		return result, nil
+	}
+	basePath := request.Motion.Path()
+	if basePath == "" {
+		basePath = request.FallbackPath
+	}
+	result.BasePath = basePath
+	if basePath == "" {
+		return result, nil
+	}
+	if request.Writer == nil {
+		return result, fmt.Errorf("保存リポジトリがありません")
+	}
+
+	rangeMotion, err := BuildRangeMotion(request.Motion, request.Start, request.End)
+	if err != nil {
+		return result, err
+	}
+	if rangeMotion == nil {
+		return result, nil
+	}
+
+	rangePath, skipped, err := ResolveOutputPath(basePath, OutputOpRange, request.Output)
+	if err != nil {
+		return result, err
+	}
+	result.RangePath = rangePath
+	result.OutputSkipped = skipped
+	if rangePath == "" || skipped {
+		return result, nil
+	}
+	if err := request.Writer.Save(rangePath, rangeMotion, request.SaveOptions); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// extractRangeFrames は1トラック分のキーフレームから区間を取り出し、start を0フレームとして返す。
+func extractRangeFrames[T motion.IBaseFrame](frames frameIterator[T], start motion.Frame, end motion.Frame, adapter frameAdapter[T]) []T {
+	indexes, values := sortedFrames(frames)
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	// previous は frame より前の最後のキー、next は frame より後の最初のキーの位置を返す。
+	previous := func(frame motion.Frame) (motion.Frame, bool) {
+		i := sort.Search(len(indexes), func(i int) bool { return indexes[i] >= frame })
+		if i == 0 {
+			return 0, false
+		}
+		return indexes[i-1], true
+	}
+	next := func(frame motion.Frame) (motion.Frame, bool) {
+		i := sort.Search(len(indexes), func(i int) bool { return indexes[i] > frame })
+		if i == len(indexes) {
+			return 0, false
+		}
+		return indexes[i], true
+	}
+	ratio := func(frame motion.Frame, from motion.Frame, to motion.Frame) float64 {
+		if to <= from {
+			return 0
+		}
+		return float64(frame-from) / float64(to-from)
+	}
+
+	out := make([]T, 0)
+	if value, ok := values[start]; ok {
+		out = append(out, adapter.clone(value, 0))
+	} else if adapter.step {
+		if before, ok := previous(start); ok {
+			out = append(out, adapter.clone(values[before], 0))
+		}
+	} else {
+		out = append(out, adapter.clone(adapter.interpolate(start), 0))
+	}
+
+	for _, frame := range indexes {
+		if frame <= start || frame > end {
+			continue
+		}
+		value := adapter.clone(values[frame], frame-start)
+		// start をまたぐ区間は、元の補間曲線のうち start 以降の部分だけを使う。
+		if before, ok := previous(frame); ok && before < start && adapter.splitCurves != nil {
+			adapter.splitCurves(value, values[frame], ratio(start, before, frame), 1)
+		}
+		out = append(out, value)
+	}
+
+	if adapter.step || end == start {
+		return out
+	}
+	if _, ok := values[end]; ok {
+		return out
+	}
+	value := adapter.clone(adapter.interpolate(end), end-start)
+	if after, ok := next(end); ok && adapter.splitCurves != nil {
+		// end をまたぐ区間は、元の補間曲線のうち end までの部分だけを使う。
+		from := 0.0
+		before, hasBefore := previous(end)
+		if hasBefore && before < start {
+			from = ratio(start, before, after)
+		}
+		if hasBefore {
+			adapter.splitCurves(value, values[after], from, ratio(end, before, after))
+		}
+	}
+	return append(out, value)
+}
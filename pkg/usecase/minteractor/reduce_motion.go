@@ -0,0 +1,391 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// ReduceOptions はキーの間引きの許容誤差を表す。
+type ReduceOptions struct {
+	// AngleTolerance はボーンの回転の許容誤差（度）。
+	AngleTolerance float64
+	// PositionTolerance はボーンの位置の許容誤差。
+	PositionTolerance float64
+	// MorphTolerance はモーフの値の許容誤差。
+	MorphTolerance float64
+	// FitCurves は間引いた区間に補間曲線を当てはめるかを表す。false の場合は線形補間で再現できるキーだけを間引く。
+	FitCurves bool
+}
+
+// DefaultReduceOptions は画面の初期値となる間引きの設定を返す。
+func DefaultReduceOptions() ReduceOptions {
+	return ReduceOptions{AngleTolerance: 0.5, PositionTolerance: 0.05, MorphTolerance: 0.01, FitCurves: true}
+}
+
+// ReducedTrack は1トラック分の間引きの結果を表す。
+type ReducedTrack struct {
+	Section DiffSection
+	Name    string
+	// Before/After は間引く前後のキー数。
+	Before int
+	After  int
+	// MaxAngle はボーンの回転の最大誤差（度）。
+	MaxAngle float64
+	// MaxPosition はボーンの位置の最大誤差。
+	MaxPosition float64
+	// MaxValue はモーフの値の最大誤差。
+	MaxValue float64
+}
+
+// ReduceResult はキーの間引きの結果を表す。
+type ReduceResult struct {
+	// Tracks はボーン→モーフの順の全トラックの結果。
+	Tracks []ReducedTrack
+}
+
+// KeyCounts は間引く前後のキー数の合計を返す。
+func (r ReduceResult) KeyCounts() (int, int) {
+	before, after := 0, 0
+	for _, track := range r.Tracks {
+		before += track.Before
+		after += track.After
+	}
+	return before, after
+}
+
+// ReduceMotionSaveRequest はキー間引きモーション保存の入力を表す。
+type ReduceMotionSaveRequest struct {
+	Motion       *motion.VmdMotion
+	FallbackPath string
+	Writer       moutput.IFileWriter
+	SaveOptions  moutput.SaveOptions
+	Reduce       ReduceOptions
+	// Output は保存先のファイル名テンプレート・フォルダ・重複時の扱い。
+	Output OutputPathOptions
+}
+
+// ReduceMotionSaveResult はキー間引きモーション保存の結果を表す。
+type ReduceMotionSaveResult struct {
+	BasePath    string
+	ReducedPath string
+	Reduce      ReduceResult
+	// OutputSkipped は保存先の重複時の扱いにより保存しなかったかを表す。
+	OutputSkipped bool
+}
+
+// boneSegmentFit はボーンの1区間を1本の補間で表した結果を表す。
+type boneSegmentFit struct {
+	curves      *motion.BoneCurves
+	maxAngle    float64
+	maxPosition float64
+}
+
+// BuildReducedMotion はボーン/モーフのキーのうち、前後のキーの補間で許容誤差内に再現できるものを間引いたモーションを複製する。
+// 誤差は各フレームで元のモーションと比べる。カメラ・照明・セルフ影・IKはそのまま残す。
+func BuildReducedMotion(source *motion.VmdMotion, options ReduceOptions) (*motion.VmdMotion, ReduceResult, error) {
+	result := ReduceResult{}
+	if source == nil {
+		return nil, result, nil
+	}
+	if options.AngleTolerance < 0 || options.PositionTolerance < 0 || options.MorphTolerance < 0 {
+		return nil, result, fmt.Errorf("間引きの許容誤差が不正です: 回転 %v / 位置 %v / モーフ %v",
+			options.AngleTolerance, options.PositionTolerance, options.MorphTolerance)
+	}
+	copied, err := source.Copy()
+	if err != nil {
+		return nil, result, err
+	}
+
+	copied.BoneFrames = motion.NewBoneFrames()
+	if source.BoneFrames != nil {
+		for _, name := range source.BoneFrames.Names() {
+			frames := source.BoneFrames.Get(name)
+			if frames == nil || frames.Len() == 0 {
+				continue
+			}
+			reduced, track := reduceBoneFrames(frames, options)
+			track.Name = name
+			nameFrames := motion.NewBoneNameFrames(name)
+			for _, value := range reduced {
+				nameFrames.Append(value)
+			}
+			copied.BoneFrames.Update(nameFrames)
+			result.Tracks = append(result.Tracks, track)
+		}
+	}
+
+	copied.MorphFrames = motion.NewMorphFrames()
+	if source.MorphFrames != nil {
+		for _, name := range source.MorphFrames.Names() {
+			frames := source.MorphFrames.Get(name)
+			if frames == nil || frames.Len() == 0 {
+				continue
+			}
+			reduced, track := reduceMorphFrames(frames, options)
+			track.Name = name
+			nameFrames := motion.NewMorphNameFrames(name)
+			for _, value := range reduced {
+				nameFrames.Append(value)
+			}
+			copied.MorphFrames.Update(nameFrames)
+			result.Tracks = append(result.Tracks, track)
+		}
+	}
+	return &copied, result, nil
+}
+
+// SaveReducedMotion はキーを間引いたモーションを保存する。
+func SaveReducedMotion(request ReduceMotionSaveRequest) (*ReduceMotionSaveResult, error) {
+	result := &ReduceMotionSaveResult{}
+	if request.Motion == nil {
+		return result, nil
+	}
+	basePath := request.Motion.Path()
+	if basePath == "" {
+		basePath = request.FallbackPath
+	}
+	result.BasePath = basePath
+	if basePath == "" {
+		return result, nil
+	}
+	if request.Writer == nil {
+		return result, fmt.Errorf("保存リポジトリがありません")
+	}
+
+	reducedMotion, reduceResult, err := BuildReducedMotion(request.Motion, request.Reduce)
+	if err != nil {
+		return result, err
+	}
+	if reducedMotion == nil {
+		return result, nil
+	}
+	result.Reduce = reduceResult
+
+	reducedPath, skipped, err := ResolveOutputPath(basePath, OutputOpReduce, request.Output)
+	if err != nil {
+		return result, err
+	}
+	result.ReducedPath = reducedPath
+	result.OutputSkipped = skipped
+	if reducedPath == "" || skipped {
+		return result, nil
+	}
+	if err := request.Writer.Save(reducedPath, reducedMotion, request.SaveOptions); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// reduceBoneFrames は1ボーン分のキーを間引く。
+func reduceBoneFrames(frames *motion.BoneNameFrames, options ReduceOptions) ([]*motion.BoneFrame, ReducedTrack) {
+	indexes, values := sortedFrames[*motion.BoneFrame](frames)
+	track := ReducedTrack{Section: DiffSectionBone, Before: len(indexes)}
+	out := make([]*motion.BoneFrame, 0, len(indexes))
+	if len(indexes) == 0 {
+		return out, track
+	}
+
+	// 元のモーションの各フレームの値。区間の誤差はこの値と比べる。
+	first := indexes[0]
+	count := int(indexes[len(indexes)-1]-first) + 1
+	positions := make([]ikVec, count)
+	rotations := make([]ikQuat, count)
+	for i := range count {
+		value := frames.Get(first + motion.Frame(i))
+		if value == nil {
+			rotations[i] = ikIdentity
+			continue
+		}
+		positions[i] = newIkVec(value.Position)
+		rotations[i] = newIkQuat(value.Rotation).normalized()
+	}
+
+	out = append(out, cloneBoneFrame(values[first], first))
+	for anchor := 0; anchor < len(indexes)-1; {
+		from := int(indexes[anchor] - first)
+		end, fit := farthestReducibleKey(anchor, len(indexes), func(end int) (boneSegmentFit, bool) {
+			return fitBoneSegment(positions, rotations, from, int(indexes[end]-first), options)
+		})
+		value := cloneBoneFrame(values[indexes[end]], indexes[end])
+		if end > anchor+1 {
+			value.Curves = fit.curves
+			track.MaxAngle = math.Max(track.MaxAngle, fit.maxAngle)
+			track.MaxPosition = math.Max(track.MaxPosition, fit.maxPosition)
+		}
+		out = append(out, value)
+		anchor = end
+	}
+	track.After = len(out)
+	return out, track
+}
+
+// reduceMorphFrames は1モーフ分のキーを間引く。モーフは線形補間のため補間曲線は当てはめない。
+func reduceMorphFrames(frames *motion.MorphNameFrames, options ReduceOptions) ([]*motion.MorphFrame, ReducedTrack) {
+	indexes, values := sortedFrames[*motion.MorphFrame](frames)
+	track := ReducedTrack{Section: DiffSectionMorph, Before: len(indexes)}
+	out := make([]*motion.MorphFrame, 0, len(indexes))
+	if len(indexes) == 0 {
+		return out, track
+	}
+
+	first := indexes[0]
+	count := int(indexes[len(indexes)-1]-first) + 1
+	ratios := make([]float64, count)
+	for i := range count {
+		if value := frames.Get(first + motion.Frame(i)); value != nil {
+			ratios[i] = value.Ratio
+		}
+	}
+
+	out = append(out, cloneMorphFrame(values[first], first))
+	for anchor := 0; anchor < len(indexes)-1; {
+		from := int(indexes[anchor] - first)
+		end, maxValue := farthestReducibleKey(anchor, len(indexes), func(end int) (float64, bool) {
+			to := int(indexes[end] - first)
+			worst := 0.0
+			for i := from + 1; i < to; i++ {
+				expected := ratios[from] + (ratios[to]-ratios[from])*float64(i-from)/float64(to-from)
+				worst = math.Max(worst, math.Abs(expected-ratios[i]))
+			}
+			return worst, worst <= options.MorphTolerance
+		})
+		if end > anchor+1 {
+			track.MaxValue = math.Max(track.MaxValue, maxValue)
+		}
+		out = append(out, cloneMorphFrame(values[indexes[end]], indexes[end]))
+		anchor = end
+	}
+	track.After = len(out)
+	return out, track
+}
+
+// farthestReducibleKey は anchor のキーから1区間で表せる最も遠いキーの位置と、その区間の当てはめ結果を返す。
+// 区間を倍々に広げて表せなくなる位置を見つけ、二分探索で詰める。隣のキーまでは元の補間のまま必ず表せる。
+func farthestReducibleKey[F any](anchor int, count int, fit func(end int) (F, bool)) (int, F) {
+	var best F
+	good, bad := anchor+1, count
+	for step := 1; ; step *= 2 {
+		probe := anchor + 1 + step
+		if probe >= bad {
+			break
+		}
+		result, ok := fit(probe)
+		if !ok {
+			bad = probe
+			break
+		}
+		good, best = probe, result
+	}
+	for bad-good > 1 {
+		probe := (good + bad) / 2
+		if result, ok := fit(probe); ok {
+			good, best = probe, result
+		} else {
+			bad = probe
+		}
+	}
+	return good, best
+}
+
+// fitBoneSegment はボーンの [from, to] のフレームを両端のキーの補間で表せるか判定する。
+// まず線形補間で試し、許容誤差を超える場合は FitCurves のときに軸ごとの補間曲線を当てはめる。
+func fitBoneSegment(positions []ikVec, rotations []ikQuat, from int, to int, options ReduceOptions) (boneSegmentFit, bool) {
+	linear := motion.NewBoneCurves()
+	linear.TranslateX, linear.TranslateY = mmath.NewCurve(), mmath.NewCurve()
+	linear.TranslateZ, linear.Rotate = mmath.NewCurve(), mmath.NewCurve()
+	fit := measureBoneSegment(positions, rotations, from, to, linear)
+	if fit.maxAngle <= options.AngleTolerance && fit.maxPosition <= options.PositionTolerance {
+		return fit, true
+	}
+	if !options.FitCurves {
+		return fit, false
+	}
+
+	// 補間曲線の値は両端の間を単調に進むため、戻りや行き過ぎが許容誤差を超える区間は当てはめずに諦める。
+	for axis := range 3 {
+		if !monotoneWithin(from, to, func(i int) float64 {
+			return positions[i][axis] - positions[from][axis]
+		}, positions[to][axis]-positions[from][axis], options.PositionTolerance) {
+			return fit, false
+		}
+	}
+	if !monotoneWithin(from, to, func(i int) float64 {
+		return rotations[from].angleTo(rotations[i]) * 180 / math.Pi
+	}, rotations[from].angleTo(rotations[to])*180/math.Pi, options.AngleTolerance) {
+		return fit, false
+	}
+
+	xs := make([]float64, 0, to-from-1)
+	for i := from + 1; i < to; i++ {
+		xs = append(xs, float64(i-from)/float64(to-from))
+	}
+	// progress は各フレームの値が両端の間のどの位置にあるかの割合に補間曲線を当てはめる。
+	// 軸ごとの誤差は合成した誤差より小さく収める必要があるため、許容誤差の半分を目安にする。
+	progress := func(value func(i int) float64, total float64, tolerance float64) *mmath.Curve {
+		if math.Abs(total) < 1e-9 {
+			return mmath.NewCurve()
+		}
+		ys := make([]float64, 0, len(xs))
+		for i := from + 1; i < to; i++ {
+			ys = append(ys, value(i)/total)
+		}
+		return fitBezierCurve(xs, ys, tolerance/2/math.Abs(total))
+	}
+	curves := motion.NewBoneCurves()
+	axes := []**mmath.Curve{&curves.TranslateX, &curves.TranslateY, &curves.TranslateZ}
+	for axis, curve := range axes {
+		*curve = progress(func(i int) float64 {
+			return positions[i][axis] - positions[from][axis]
+		}, positions[to][axis]-positions[from][axis], options.PositionTolerance)
+	}
+	curves.Rotate = progress(func(i int) float64 {
+		return rotations[from].angleTo(rotations[i])
+	}, rotations[from].angleTo(rotations[to]), options.AngleTolerance*math.Pi/180)
+
+	fit = measureBoneSegment(positions, rotations, from, to, curves)
+	return fit, fit.maxAngle <= options.AngleTolerance && fit.maxPosition <= options.PositionTolerance
+}
+
+// measureBoneSegment は [from, to] を補間曲線で補間したときの元の値との最大誤差を求める。
+func measureBoneSegment(positions []ikVec, rotations []ikQuat, from int, to int, curves *motion.BoneCurves) boneSegmentFit {
+	fit := boneSegmentFit{curves: curves}
+	curveX := newBezierCurve(curves.TranslateX)
+	curveY := newBezierCurve(curves.TranslateY)
+	curveZ := newBezierCurve(curves.TranslateZ)
+	curveRotate := newBezierCurve(curves.Rotate)
+	start, end := positions[from], positions[to]
+	for i := from + 1; i < to; i++ {
+		x := float64(i-from) / float64(to-from)
+		position := ikVec{
+			start[0] + (end[0]-start[0])*curveX.valueAt(x),
+			start[1] + (end[1]-start[1])*curveY.valueAt(x),
+			start[2] + (end[2]-start[2])*curveZ.valueAt(x),
+		}
+		rotation := rotations[from].slerp(rotations[to], curveRotate.valueAt(x))
+		fit.maxPosition = math.Max(fit.maxPosition, position.sub(positions[i]).length())
+		fit.maxAngle = math.Max(fit.maxAngle, rotation.angleTo(rotations[i])*180/math.Pi)
+	}
+	return fit
+}
+
+// monotoneWithin は (from, to) の各フレームの値が 0 から total へ単調に進み、戻りや行き過ぎが tolerance 以内か判定する。
+func monotoneWithin(from int, to int, value func(i int) float64, total float64, tolerance float64) bool {
+	sign := 1.0
+	if total < 0 {
+		sign, total = -1, -total
+	}
+	reached := 0.0
+	for i := from + 1; i < to; i++ {
+		current := value(i) * sign
+		if current < -tolerance || current > total+tolerance || reached-current > tolerance {
+			return false
+		}
+		reached = math.Max(reached, current)
+	}
+	return true
+}
@@ -0,0 +1,313 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// RenameConflictPolicy は変換先の名前に既にキーがある場合の扱いを表す。
+type RenameConflictPolicy string
+
+// 変換先の重複時の扱い一覧。
+const (
+	// RenameOverwrite は変換先の既存キーを削除して置き換える。複数の変換が同じ名前に重なる場合は先の変換だけを行う。
+	RenameOverwrite RenameConflictPolicy = "overwrite"
+	// RenameSkip は変換せず、変換元の名前のまま残す。
+	RenameSkip RenameConflictPolicy = "skip"
+	// RenameMerge はフレームごとに統合する。同じフレームは変換元のキーを優先する。
+	RenameMerge RenameConflictPolicy = "merge"
+)
+
+// RenameConflictPolicies は画面で扱う重複時の扱いの表示順。
+var RenameConflictPolicies = []RenameConflictPolicy{RenameOverwrite, RenameSkip, RenameMerge}
+
+// RenamedTrack は名前を変換したトラックを表す。
+type RenamedTrack struct {
+	Kind string
+	From string
+	To   string
+	// Conflict は変換先の名前に既にキーがあったかを表す。
+	Conflict bool
+	// TooLong は変換先の名前がVMDの名前欄に収まらないため変換しなかったかを表す。
+	TooLong bool
+}
+
+// RenameMotionSaveRequest は名前変換モーション保存の入力を表す。
+type RenameMotionSaveRequest struct {
+	Motion       *motion.VmdMotion
+	FallbackPath string
+	Writer       moutput.IFileWriter
+	SaveOptions  moutput.SaveOptions
+	// Mapping はモーション側の名前からモデル側の名前への対応。
+	Mapping *AliasDictionary
+	// Policy は変換先の名前に既にキーがある場合の扱い。空の場合は RenameSkip とする。
+	Policy RenameConflictPolicy
+	// Output は保存先のファイル名テンプレート・フォルダ・重複時の扱い。
+	Output OutputPathOptions
+}
+
+// RenameMotionSaveResult は名前変換モーション保存の結果を表す。
+type RenameMotionSaveResult struct {
+	BasePath    string
+	RenamedPath string
+	Renamed     []RenamedTrack
+	// Skipped は重複（Conflict）または名前欄の超過（TooLong）のため変換しなかったトラック。
+	Skipped []RenamedTrack
+	// OutputSkipped は保存先の重複時の扱いにより保存しなかったかを表す。
+	OutputSkipped bool
+}
+
+// BuildRenameMapping は判定結果から名前変換の対応を作る。
+// 別名辞書・英名で対応付けた名前に加え、useSuggestions が true の場合はNG名の最有力候補も使う。
+// 切り詰めで一致した名前はMMDがそのまま対応付けるうえ、モデル側の名前はVMDの名前欄に収まらないため対象外とする。
+func BuildRenameMapping(result CheckResult, useSuggestions bool) *AliasDictionary {
+	mapping := NewAliasDictionary()
+	for _, entries := range [][]MappedName{result.MappedBones, result.EnglishBones} {
+		for _, entry := range entries {
+			mapping.Bones[entry.Name] = entry.Target
+		}
+	}
+	for _, entries := range [][]MappedName{result.MappedMorphs, result.EnglishMorphs} {
+		for _, entry := range entries {
+			mapping.Morphs[entry.Name] = entry.Target
+		}
+	}
+	if !useSuggestions {
+		return mapping
+	}
+	for _, entry := range result.NgBones {
+		if suggestion, ok := TopSuggestion(result.NgBoneSuggestions, entry.Name); ok {
+			mapping.Bones[entry.Name] = suggestion.Name
+		}
+	}
+	for _, entry := range result.NgMorphs {
+		if suggestion, ok := TopSuggestion(result.NgMorphSuggestions, entry.Name); ok {
+			mapping.Morphs[entry.Name] = suggestion.Name
+		}
+	}
+	return mapping
+}
+
+// BuildRenamedMotion は対応に従ってボーン/モーフのトラック名を変換したモーションを複製する。
+// 変換は全て元のモーションのトラックから解決するため、入れ替え（A→B, B→A）や連鎖（A→B, B→C）でもキーを失わない。
+// 変換先がVMDの名前欄に収まらないトラックは変換せず、TooLong を立てて skipped に入れる。
+func BuildRenamedMotion(source *motion.VmdMotion, mapping *AliasDictionary, policy RenameConflictPolicy) (*motion.VmdMotion, []RenamedTrack, []RenamedTrack, error) {
+	if source == nil {
+		return nil, nil, nil, nil
+	}
+	switch policy {
+	case "":
+		policy = RenameSkip
+	case RenameOverwrite, RenameSkip, RenameMerge:
+	default:
+		return nil, nil, nil, fmt.Errorf("未対応の重複時の扱いです: %s", policy)
+	}
+	copied, err := source.Copy()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if mapping == nil {
+		return &copied, nil, nil, nil
+	}
+	renamed := make([]RenamedTrack, 0)
+	skipped := make([]RenamedTrack, 0)
+
+	if source.BoneFrames != nil {
+		plan, boneRenamed, boneSkipped := planRenames(ScoreKindBone, source.BoneFrames.Names(), mapping.Bones, policy)
+		renamed = append(renamed, boneRenamed...)
+		skipped = append(skipped, boneSkipped...)
+		copied.BoneFrames = motion.NewBoneFrames()
+		for _, name := range plan.names {
+			target := motion.NewBoneNameFrames(name)
+			for _, from := range plan.sources[name] {
+				source.BoneFrames.Get(from).ForEach(func(frame motion.Frame, value *motion.BoneFrame) bool {
+					if value == nil {
+						return true
+					}
+					if target.Has(frame) {
+						target.Delete(frame)
+					}
+					target.Append(cloneBoneFrame(value, frame))
+					return true
+				})
+			}
+			copied.BoneFrames.Update(target)
+		}
+	}
+	if source.MorphFrames != nil {
+		plan, morphRenamed, morphSkipped := planRenames(ScoreKindMorph, source.MorphFrames.Names(), mapping.Morphs, policy)
+		renamed = append(renamed, morphRenamed...)
+		skipped = append(skipped, morphSkipped...)
+		copied.MorphFrames = motion.NewMorphFrames()
+		for _, name := range plan.names {
+			target := motion.NewMorphNameFrames(name)
+			for _, from := range plan.sources[name] {
+				source.MorphFrames.Get(from).ForEach(func(frame motion.Frame, value *motion.MorphFrame) bool {
+					if value == nil {
+						return true
+					}
+					if target.Has(frame) {
+						target.Delete(frame)
+					}
+					target.Append(cloneMorphFrame(value, frame))
+					return true
+				})
+			}
+			copied.MorphFrames.Update(target)
+		}
+	}
+	if len(renamed) == 0 {
+		renamed = nil
+	}
+	if len(skipped) == 0 {
+		skipped = nil
+	}
+	return &copied, renamed, skipped, nil
+}
+
+// SaveRenamedMotion は名前を変換したモーションを保存する。
+func SaveRenamedMotion(request RenameMotionSaveRequest) (*RenameMotionSaveResult, error) {
+	result := &RenameMotionSaveResult{}
+	if request.Motion == nil {
+		return result, nil
+	}
+	basePath := request.Motion.Path()
+	if basePath == "" {
+		basePath = request.FallbackPath
+	}
+	result.BasePath = basePath
+	if basePath == "" {
+		return result, nil
+	}
+	if request.Writer == nil {
+		return result, fmt.Errorf("保存リポジトリがありません")
+	}
+
+	renamedMotion, renamed, skipped, err := BuildRenamedMotion(request.Motion, request.Mapping, request.Policy)
+	if err != nil {
+		return result, err
+	}
+	if renamedMotion == nil {
+		return result, nil
+	}
+	result.Renamed = renamed
+	result.Skipped = skipped
+
+	renamedPath, outputSkipped, err := ResolveOutputPath(basePath, OutputOpRenamed, request.Output)
+	if err != nil {
+		return result, err
+	}
+	result.RenamedPath = renamedPath
+	result.OutputSkipped = outputSkipped
+	if renamedPath == "" || outputSkipped {
+		return result, nil
+	}
+	if err := request.Writer.Save(renamedPath, renamedMotion, request.SaveOptions); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// renamePlan は変換後のトラックごとに、キーを集める元のモーションのトラックを表す。
+type renamePlan struct {
+	// names は変換後のトラック名の出力順。
+	names []string
+	// sources は変換後のトラック名ごとの元のトラック名。後のトラックのキーを同じフレームで優先する。
+	sources map[string][]string
+}
+
+// planRenames は元のトラック名の一覧と対応から、変換後のトラックの構成を決める。
+// RenameSkip では、変換後も残るトラックや先に変換するトラックと重なる変換を、重ならなくなるまで取り除く。
+// RenameOverwrite で置き換えるのは変換後も残るトラックだけで、先に変換するトラックと重なる変換は取り除く。
+func planRenames(kind string, names []string, mapping map[string]string, policy RenameConflictPolicy) (renamePlan, []RenamedTrack, []RenamedTrack) {
+	exists := make(map[string]bool, len(names))
+	for _, name := range names {
+		exists[name] = true
+	}
+	candidates := make([]RenamedTrack, 0)
+	skipped := make([]RenamedTrack, 0)
+	for _, from := range sortedKeys(mapping) {
+		to := mapping[from]
+		if from == to || !exists[from] {
+			continue
+		}
+		track := RenamedTrack{Kind: kind, From: from, To: to}
+		if !fitsVmdName(to) {
+			track.TooLong = true
+			skipped = append(skipped, track)
+			continue
+		}
+		candidates = append(candidates, track)
+	}
+	active := make(map[string]bool, len(candidates))
+	for _, track := range candidates {
+		active[track.From] = true
+	}
+	if policy != RenameMerge {
+		for changed := true; changed; {
+			changed = false
+			claimed := make(map[string]bool, len(candidates))
+			for _, track := range candidates {
+				if !active[track.From] {
+					continue
+				}
+				kept := exists[track.To] && !active[track.To]
+				if (policy == RenameSkip && kept) || claimed[track.To] {
+					active[track.From] = false
+					changed = true
+					continue
+				}
+				claimed[track.To] = true
+			}
+		}
+	}
+
+	plan := renamePlan{names: make([]string, 0, len(names)), sources: make(map[string][]string, len(names))}
+	for _, name := range names {
+		if !active[name] {
+			plan.names = append(plan.names, name)
+			plan.sources[name] = []string{name}
+		}
+	}
+	renamed := make([]RenamedTrack, 0, len(candidates))
+	for _, track := range candidates {
+		if !active[track.From] {
+			track.Conflict = true
+			skipped = append(skipped, track)
+			continue
+		}
+		existing, occupied := plan.sources[track.To]
+		track.Conflict = occupied
+		switch {
+		case !occupied:
+			plan.names = append(plan.names, track.To)
+			plan.sources[track.To] = []string{track.From}
+		case policy == RenameMerge:
+			plan.sources[track.To] = append(existing, track.From)
+		default:
+			plan.sources[track.To] = []string{track.From}
+		}
+		renamed = append(renamed, track)
+	}
+	return plan, renamed, skipped
+}
+
+// fitsVmdName は名前がShift-JISでVMDの名前欄に収まるか判定する。
+func fitsVmdName(name string) bool {
+	encoded, ok := encodeShiftJIS(name)
+	return ok && len(encoded) <= vmdNameByteLength
+}
+
+// sortedKeys は対応表の変換元の名前を名前順に返す。
+func sortedKeys(mapping map[string]string) []string {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"testing"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+)
+
+// newTestRenameMotion は名前ごとにX位置の異なるキーを1つずつ持つボーンのモーションを作る。
+func newTestRenameMotion(positions map[string]float64) *motion.VmdMotion {
+	motionData := motion.NewVmdMotion("")
+	motionData.BoneFrames = motion.NewBoneFrames()
+	for name, x := range positions {
+		frames := motion.NewBoneNameFrames(name)
+		frame := motion.NewBoneFrame(0)
+		frame.Position = &mmath.Vec3{X: x}
+		frames.Append(frame)
+		motionData.BoneFrames.Update(frames)
+	}
+	return motionData
+}
+
+// assertBonePositionX はボーンの0フレームのX位置を確認する。
+func assertBonePositionX(t *testing.T, motionData *motion.VmdMotion, name string, want float64) {
+	t.Helper()
+	if !motionData.BoneFrames.Contains(name) {
+		t.Fatalf("%s のトラックがありません", name)
+	}
+	frame := motionData.BoneFrames.Get(name).Get(0)
+	if frame == nil || frame.Position == nil || frame.Position.X != want {
+		t.Fatalf("%s のX位置が %v ではありません: %+v", name, want, frame)
+	}
+}
+
+func TestBuildRenamedMotionOverwriteSameTarget(t *testing.T) {
+	source := newTestRenameMotion(map[string]float64{"A": 1, "B": 2})
+	mapping := NewAliasDictionary()
+	mapping.Bones["A"] = "X"
+	mapping.Bones["B"] = "X"
+
+	renamed, renamedTracks, skipped, err := BuildRenamedMotion(source, mapping, RenameOverwrite)
+	if err != nil {
+		t.Fatalf("BuildRenamedMotion: %v", err)
+	}
+	if len(renamedTracks) != 1 || renamedTracks[0].From != "A" || renamedTracks[0].Conflict {
+		t.Fatalf("変換したトラック = %+v", renamedTracks)
+	}
+	if len(skipped) != 1 || skipped[0].From != "B" || skipped[0].To != "X" || !skipped[0].Conflict {
+		t.Fatalf("変換しなかったトラック = %+v", skipped)
+	}
+	// 後の変換で先の変換元のキーを捨てず、変換しなかったトラックは元の名前のまま残る。
+	assertBonePositionX(t, renamed, "X", 1)
+	assertBonePositionX(t, renamed, "B", 2)
+	if renamed.BoneFrames.Contains("A") {
+		t.Fatalf("変換元のトラックが残っています")
+	}
+}
+
+func TestBuildRenamedMotionOverwriteExisting(t *testing.T) {
+	source := newTestRenameMotion(map[string]float64{"A": 1, "X": 3})
+	mapping := NewAliasDictionary()
+	mapping.Bones["A"] = "X"
+
+	renamed, renamedTracks, skipped, err := BuildRenamedMotion(source, mapping, RenameOverwrite)
+	if err != nil {
+		t.Fatalf("BuildRenamedMotion: %v", err)
+	}
+	if len(renamedTracks) != 1 || !renamedTracks[0].Conflict || len(skipped) != 0 {
+		t.Fatalf("変換したトラック = %+v, 変換しなかったトラック = %+v", renamedTracks, skipped)
+	}
+	// 変換後も残るトラックは置き換える。
+	assertBonePositionX(t, renamed, "X", 1)
+	if renamed.BoneFrames.Contains("A") {
+		t.Fatalf("変換元のトラックが残っています")
+	}
+}
@@ -1,10 +1,96 @@
 // 指示: miu200521358
 package minteractor
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+)
+
 // CheckResult はOK/NG判定の結果一覧を表す。
 type CheckResult struct {
-	OkBones  []string
-	OkMorphs []string
-	NgBones  []string
-	NgMorphs []string
+	// OkBones/OkMorphs はモデルのインデックス順に並ぶ。
+	OkBones  []TrackEntry
+	OkMorphs []TrackEntry
+	// NgBones/NgMorphs はキーフレーム数の多い順に並ぶ。
+	NgBones  []TrackEntry
+	NgMorphs []TrackEntry
+	// OkMorphGroups/NgMorphGroups はOK/NGモーフをモデルの操作パネル区分ごとにまとめたもの。
+	OkMorphGroups []MorphPanelGroup
+	NgMorphGroups []MorphPanelGroup
+
+	// MappedBones は別名辞書でモデルのボーンに対応付けたボーン。
+	MappedBones []MappedName
+	// MappedMorphs は別名辞書でモデルのモーフに対応付けたモーフ。
+	MappedMorphs []MappedName
+	// EnglishBones はモデルの英名でのみ一致したボーン。
+	EnglishBones []MappedName
+	// EnglishMorphs はモデルの英名でのみ一致したモーフ。
+	EnglishMorphs []MappedName
+	// TruncatedBones/TruncatedMorphs はVMDの15バイト制限で切り詰められ、
+	// モデルの1つの名前にだけ前方一致したボーン/モーフ。MMDでは一致として再生される。
+	TruncatedBones  []MappedName
+	TruncatedMorphs []MappedName
+	// AmbiguousBones/AmbiguousMorphs は切り詰められた名前が複数の名前に前方一致したボーン/モーフ。
+	AmbiguousBones  []AmbiguousName
+	AmbiguousMorphs []AmbiguousName
+
+	// NgBoneSuggestions はNGボーン名ごとの候補ボーン名（距離順）。
+	NgBoneSuggestions map[string][]NameSuggestion
+	// NgMorphSuggestions はNGモーフ名ごとの候補モーフ名（距離順）。
+	NgMorphSuggestions map[string][]NameSuggestion
+
+	// ProfileAudit はモーションが動かしているがモデルにないプロファイルボーン。
+	ProfileAudit []ProfileAuditEntry
+	// LipSyncAudit はモーションが動かしているがモデルにない母音/まばたきモーフ。
+	LipSyncAudit []LipSyncAuditEntry
+	// IkBones はモーションでIKを切り替えているボーンとモデル上の状態。
+	IkBones []IkFrameEntry
+}
+
+// TrackStats はモーション内の1トラックのキーフレーム統計を表す。
+type TrackStats struct {
+	KeyCount   int
+	FirstFrame motion.Frame
+	LastFrame  motion.Frame
+	// Constant は全キーフレームが同じ値であるかを表す。
+	Constant bool
+}
+
+// TrackEntry は判定結果の1トラックを表す。
+type TrackEntry struct {
+	Name string
+	TrackStats
+}
+
+// MappedName は別名などで対応付けたモーション側のトラックと対応先を表す。
+type MappedName struct {
+	TrackEntry
+	Target string
+}
+
+// FormatIkToggles はIK切替フレームを「OFF 10, ON 50」形式の文字列にする。
+func FormatIkToggles(toggles []IkToggle) string {
+	parts := make([]string, len(toggles))
+	for i, toggle := range toggles {
+		state := "OFF"
+		if toggle.Enabled {
+			state = "ON"
+		}
+		parts[i] = fmt.Sprintf("%s %v", state, toggle.Frame)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// TrackNames はトラック一覧から名前だけを取り出す。
+func TrackNames(entries []TrackEntry) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name
+	}
+	return names
 }
@@ -0,0 +1,326 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
+)
+
+// RetimeMode はモーションの時間変換の種類を表す。
+type RetimeMode string
+
+// 時間変換の種類一覧。ファイル名テンプレートの {op} にも使う。
+const (
+	// RetimeStretch は全キーのフレーム番号を倍率で伸縮する（テンポ変更）。
+	RetimeStretch RetimeMode = "stretch"
+	// RetimeResample は一定間隔のフレームでキーを打ち直す（キー密度の変更）。
+	RetimeResample RetimeMode = "resample"
+)
+
+// RetimeModes は画面で扱う時間変換の種類の表示順。
+var RetimeModes = []RetimeMode{RetimeStretch, RetimeResample}
+
+// RetimeOptions は時間変換の設定を表す。
+type RetimeOptions struct {
+	Mode RetimeMode
+	// Factor は RetimeStretch の倍率。2 で2倍の長さ（半分の速さ）になる。
+	Factor float64
+	// Interval は RetimeResample でキーを打つ間隔（フレーム）。
+	Interval motion.Frame
+	// AllowLossy は元の動きを再現できない変換を許可するかを表す。
+	AllowLossy bool
+}
+
+// RetimeIssue は時間変換で元の動きを再現できないキーを表す。
+type RetimeIssue struct {
+	Section DiffSection
+	Name    string
+	// Frame は変換後のフレーム番号。
+	Frame motion.Frame
+	// SourceFrames は対象となった変換前のキーのフレーム番号。
+	SourceFrames []motion.Frame
+}
+
+// RetimeResult は時間変換の結果を表す。
+type RetimeResult struct {
+	// Collisions は丸めにより同じフレームに重なったキー。後ろのキーを残す。
+	Collisions []RetimeIssue
+	// Dropped は打ち直しの間隔の間にあり、失われたキー。
+	Dropped []RetimeIssue
+}
+
+// IsLossy は元の動きを再現できないキーがあるか判定する。
+func (r RetimeResult) IsLossy() bool {
+	return len(r.Collisions) > 0 || len(r.Dropped) > 0
+}
+
+// RetimeMotionSaveRequest は時間変換モーション保存の入力を表す。
+type RetimeMotionSaveRequest struct {
+	Motion       *motion.VmdMotion
+	FallbackPath string
+	Writer       moutput.IFileWriter
+	SaveOptions  moutput.SaveOptions
+	Retime       RetimeOptions
+	// Output は保存先のファイル名テンプレート・フォルダ・重複時の扱い。
+	Output OutputPathOptions
+}
+
+// RetimeMotionSaveResult は時間変換モーション保存の結果を表す。
+type RetimeMotionSaveResult struct {
+	BasePath    string
+	RetimedPath string
+	Retime      RetimeResult
+	// OutputSkipped は保存先の重複時の扱いにより保存しなかったかを表す。
+	OutputSkipped bool
+}
+
+// retimeTrackResult は1トラック分の時間変換の結果を表す。
+type retimeTrackResult[T any] struct {
+	frames     []T
+	collisions []RetimeIssue
+	dropped    []RetimeIssue
+}
+
+// BuildRetimedMotion はボーン・モーフ・カメラ・照明・セルフ影・IKのキーの時間を変換したモーションを複製する。
+// 補間曲線は区間ごとに正規化されているため、伸縮ではそのまま使い、打ち直しでは元の曲線を分割して求め直す。
+// 元の動きを再現できない場合は、AllowLossy でなければ結果とともにエラーを返す。
+func BuildRetimedMotion(source *motion.VmdMotion, options RetimeOptions) (*motion.VmdMotion, RetimeResult, error) {
+	result := RetimeResult{}
+	if source == nil {
+		return nil, result, nil
+	}
+	switch options.Mode {
+	case RetimeStretch:
+		if options.Factor <= 0 || math.IsNaN(options.Factor) || math.IsInf(options.Factor, 0) {
+			return nil, result, fmt.Errorf("伸縮の倍率が不正です: %v", options.Factor)
+		}
+	case RetimeResample:
+		if options.Interval < 1 {
+			return nil, result, fmt.Errorf("打ち直しの間隔が不正です: %v", options.Interval)
+		}
+	default:
+		return nil, result, fmt.Errorf("未対応の時間変換です: %s", options.Mode)
+	}
+	copied, err := source.Copy()
+	if err != nil {
+		return nil, result, err
+	}
+	collect := func(section DiffSection, name string, collisions []RetimeIssue, dropped []RetimeIssue) {
+		for _, issue := range collisions {
+			issue.Section, issue.Name = section, name
+			result.Collisions = append(result.Collisions, issue)
+		}
+		for _, issue := range dropped {
+			issue.Section, issue.Name = section, name
+			result.Dropped = append(result.Dropped, issue)
+		}
+	}
+
+	copied.BoneFrames = motion.NewBoneFrames()
+	if source.BoneFrames != nil {
+		for _, name := range source.BoneFrames.Names() {
+			frames := source.BoneFrames.Get(name)
+			if frames == nil || frames.Len() == 0 {
+				continue
+			}
+			track := retimeFrames[*motion.BoneFrame](frames, boneFrameAdapter(frames), options)
+			nameFrames := motion.NewBoneNameFrames(name)
+			for _, value := range track.frames {
+				nameFrames.Append(value)
+			}
+			copied.BoneFrames.Update(nameFrames)
+			collect(DiffSectionBone, name, track.collisions, track.dropped)
+		}
+	}
+
+	copied.MorphFrames = motion.NewMorphFrames()
+	if source.MorphFrames != nil {
+		for _, name := range source.MorphFrames.Names() {
+			frames := source.MorphFrames.Get(name)
+			if frames == nil || frames.Len() == 0 {
+				continue
+			}
+			track := retimeFrames[*motion.MorphFrame](frames, morphFrameAdapter(frames), options)
+			nameFrames := motion.NewMorphNameFrames(name)
+			for _, value := range track.frames {
+				nameFrames.Append(value)
+			}
+			copied.MorphFrames.Update(nameFrames)
+			collect(DiffSectionMorph, name, track.collisions, track.dropped)
+		}
+	}
+
+	copied.CameraFrames = motion.NewCameraFrames()
+	if source.CameraFrames != nil && source.CameraFrames.Len() > 0 {
+		track := retimeFrames[*motion.CameraFrame](source.CameraFrames, cameraFrameAdapter(source.CameraFrames), options)
+		for _, value := range track.frames {
+			copied.CameraFrames.Append(value)
+		}
+		collect(DiffSectionCamera, "", track.collisions, track.dropped)
+	}
+
+	copied.LightFrames = motion.NewLightFrames()
+	if source.LightFrames != nil && source.LightFrames.Len() > 0 {
+		track := retimeFrames[*motion.LightFrame](source.LightFrames, lightFrameAdapter(source.LightFrames), options)
+		for _, value := range track.frames {
+			copied.LightFrames.Append(value)
+		}
+		collect(DiffSectionLight, "", track.collisions, track.dropped)
+	}
+
+	copied.ShadowFrames = motion.NewShadowFrames()
+	if source.ShadowFrames != nil && source.ShadowFrames.Len() > 0 {
+		track := retimeFrames[*motion.ShadowFrame](source.ShadowFrames, shadowFrameAdapter(source.ShadowFrames), options)
+		for _, value := range track.frames {
+			copied.ShadowFrames.Append(value)
+		}
+		collect(DiffSectionShadow, "", track.collisions, track.dropped)
+	}
+
+	copied.IkFrames = motion.NewIkFrames()
+	if source.IkFrames != nil && source.IkFrames.Len() > 0 {
+		track := retimeFrames[*motion.IkFrame](source.IkFrames, ikFrameAdapter(), options)
+		for _, value := range track.frames {
+			copied.IkFrames.Append(value)
+		}
+		collect(DiffSectionIk, "", track.collisions, track.dropped)
+	}
+
+	if result.IsLossy() && !options.AllowLossy {
+		return nil, result, fmt.Errorf("元の動きを再現できないキーがあります（重なり %d件、欠落 %d件）",
+			len(result.Collisions), len(result.Dropped))
+	}
+	return &copied, result, nil
+}
+
+// SaveRetimedMotion は時間を変換したモーションを保存する。
+func SaveRetimedMotion(request RetimeMotionSaveRequest) (*RetimeMotionSaveResult, error) {
+	result := &RetimeMotionSaveResult{}
+	if request.Motion == nil {
+		return result, nil
+	}
+	basePath := request.Motion.Path()
+	if basePath == "" {
+		basePath = request.FallbackPath
+	}
+	result.BasePath = basePath
+	if basePath == "" {
+		return result, nil
+	}
+	if request.Writer == nil {
+		return result, fmt.Errorf("保存リポジトリがありません")
+	}
+
+	retimedMotion, retimeResult, err := BuildRetimedMotion(request.Motion, request.Retime)
+	result.Retime = retimeResult
+	if err != nil {
+		return result, err
+	}
+	if retimedMotion == nil {
+		return result, nil
+	}
+
+	retimedPath, skipped, err := ResolveOutputPath(basePath, string(request.Retime.Mode), request.Output)
+	if err != nil {
+		return result, err
+	}
+	result.RetimedPath = retimedPath
+	result.OutputSkipped = skipped
+	if retimedPath == "" || skipped {
+		return result, nil
+	}
+	if err := request.Writer.Save(retimedPath, retimedMotion, request.SaveOptions); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// retimeFrames は1トラック分のキーの時間を変換する。
+func retimeFrames[T motion.IBaseFrame](frames frameIterator[T], adapter frameAdapter[T], options RetimeOptions) retimeTrackResult[T] {
+	indexes, values := sortedFrames(frames)
+	if len(indexes) == 0 {
+		return retimeTrackResult[T]{}
+	}
+	if options.Mode == RetimeResample && !adapter.step {
+		return resampleFrames(indexes, values, adapter, options.Interval)
+	}
+	factor := options.Factor
+	if options.Mode == RetimeResample {
+		// IKのON/OFFは補間しないため、打ち直しでは位置を変えずにそのまま残す。
+		factor = 1
+	}
+	return stretchFrames(indexes, values, adapter, factor)
+}
+
+// stretchFrames はキーのフレーム番号を倍率で伸縮し、整数フレームに丸める。
+// 区間の長さが変わっても正規化した補間曲線の形は変わらないため、補間曲線はそのまま使う。
+func stretchFrames[T motion.IBaseFrame](indexes []motion.Frame, values map[motion.Frame]T, adapter frameAdapter[T], factor float64) retimeTrackResult[T] {
+	result := retimeTrackResult[T]{}
+	targets := make(map[motion.Frame][]motion.Frame)
+	order := make([]motion.Frame, 0, len(indexes))
+	for _, frame := range indexes {
+		target := motion.Frame(math.Round(float64(frame) * factor))
+		if _, ok := targets[target]; !ok {
+			order = append(order, target)
+		}
+		targets[target] = append(targets[target], frame)
+	}
+	for _, target := range order {
+		sources := targets[target]
+		if len(sources) > 1 {
+			result.collisions = append(result.collisions, RetimeIssue{Frame: target, SourceFrames: sources})
+		}
+		result.frames = append(result.frames, adapter.clone(values[sources[len(sources)-1]], target))
+	}
+	return result
+}
+
+// resampleFrames は最初のキーから一定間隔でキーを打ち直す。最後のキーの位置には必ずキーを打つ。
+// 打ち直した区間が元の1区間に収まる場合は、元の補間曲線を分割して同じ動きにする。
+// 元のキーをまたぐ区間は1本の曲線で表せないため線形補間とし、またいだキーを欠落として返す。
+func resampleFrames[T motion.IBaseFrame](indexes []motion.Frame, values map[motion.Frame]T, adapter frameAdapter[T], interval motion.Frame) retimeTrackResult[T] {
+	result := retimeTrackResult[T]{}
+	first := indexes[0]
+	last := indexes[len(indexes)-1]
+	grid := make([]motion.Frame, 0)
+	for frame := first; frame < last; frame += interval {
+		grid = append(grid, frame)
+	}
+	grid = append(grid, last)
+
+	for i, frame := range grid {
+		var value T
+		if original, ok := values[frame]; ok {
+			value = adapter.clone(original, frame)
+		} else {
+			value = adapter.clone(adapter.interpolate(frame), frame)
+		}
+		if i == 0 {
+			result.frames = append(result.frames, value)
+			continue
+		}
+		from := grid[i-1]
+		// 区間の内側にある元のキー
+		low := sort.Search(len(indexes), func(j int) bool { return indexes[j] > from })
+		high := sort.Search(len(indexes), func(j int) bool { return indexes[j] >= frame })
+		if high > low {
+			inner := append([]motion.Frame(nil), indexes[low:high]...)
+			result.dropped = append(result.dropped, RetimeIssue{Frame: frame, SourceFrames: inner})
+			if adapter.resetCurves != nil {
+				adapter.resetCurves(value)
+			}
+		} else if adapter.splitCurves != nil {
+			// 区間を含む元の区間 [before, after] の補間曲線から、[from, frame] の部分を取り出す。
+			before := indexes[low-1]
+			after := indexes[high]
+			span := float64(after - before)
+			adapter.splitCurves(value, values[after], float64(from-before)/span, float64(frame-before)/span)
+		}
+		result.frames = append(result.frames, value)
+	}
+	return result
+}
@@ -3,8 +3,6 @@ package minteractor
 
 import (
 	"fmt"
-	"path/filepath"
-	"strings"
 
 	"github.com/miu200521358/mlib_go/pkg/domain/motion"
 	"github.com/miu200521358/mu_motion_viewer/pkg/usecase/port/moutput"
@@ -16,15 +14,28 @@ type SafeMotionSaveRequest struct {
 	FallbackPath string
 	Writer       moutput.IFileWriter
 	SaveOptions  moutput.SaveOptions
+	// Operations は適用する除去操作。空の場合は何も変わらないため保存せずにエラーを返す。
+	Operations []StripOperation
+	// Sanitize はNGトラックの除去に使うモデルと判定オプション。
+	Sanitize SanitizeContext
+	// Output は保存先のファイル名テンプレート・フォルダ・重複時の扱い。
+	Output OutputPathOptions
+	// DryRun が true の場合は保存せず、保存先のパスと差分だけを返す。
+	DryRun bool
 }
 
 // SafeMotionSaveResult は安全モーション保存の結果を表す。
 type SafeMotionSaveResult struct {
 	BasePath string
 	SafePath string
+	// OutputSkipped は保存先の重複時の扱いにより保存しなかったかを表す。
+	OutputSkipped bool
+	// Diff は元のモーションとの差分。DryRun の場合のみ設定する。
+	Diff *MotionDiff
+	// IkBake はIKの焼き込みの結果。焼き込みを行わなかった場合は nil。
+	IkBake *IkBakeResult
 }
 
-// BuildSafeMotion はIKフレームを空にしたモーションを複製する。
 // SaveSafeMotion は安全モーションを生成して保存する。
 func SaveSafeMotion(request SafeMotionSaveRequest) (*SafeMotionSaveResult, error) {
 	result := &SafeMotionSaveResult{}
@@ -39,21 +50,35 @@ func SaveSafeMotion(request SafeMotionSaveRequest) (*SafeMotionSaveResult, error
 	if basePath == "" {
 		return result, nil
 	}
-	if request.Writer == nil {
+	if request.Writer == nil && !request.DryRun {
 		return result, fmt.Errorf("保存リポジトリがありません")
 	}
 
-	safeMotion, err := BuildSafeMotion(request.Motion)
+	if len(request.Operations) == 0 {
+		return result, fmt.Errorf("除去する操作が選択されていません")
+	}
+	safeMotion, bakeResult, err := buildSanitizedMotion(request.Motion, request.Operations, request.Sanitize)
 	if err != nil {
 		return result, err
 	}
+	result.IkBake = bakeResult
 	if safeMotion == nil {
 		return result, nil
 	}
 
-	safePath := buildSafeMotionPath(basePath)
+	if request.DryRun {
+		diff := DiffMotion(request.Motion, safeMotion)
+		result.Diff = &diff
+		// 試行では確認ダイアログを出さず、上書きする場合のパスを返す。
+		request.Output.ConfirmOverwrite = func(string) bool { return true }
+	}
+	safePath, skipped, err := ResolveOutputPath(basePath, OutputOpSafe, request.Output)
+	if err != nil {
+		return result, err
+	}
 	result.SafePath = safePath
-	if safePath == "" {
+	result.OutputSkipped = skipped
+	if safePath == "" || skipped || request.DryRun {
 		return result, nil
 	}
 	if err := request.Writer.Save(safePath, safeMotion, request.SaveOptions); err != nil {
@@ -61,20 +86,3 @@ func SaveSafeMotion(request SafeMotionSaveRequest) (*SafeMotionSaveResult, error
 	}
 	return result, nil
 }
-
-// buildSafeMotionPath は安全モーションの保存先パスを生成する。
-func buildSafeMotionPath(path string) string {
-	if path == "" {
-		return ""
-	}
-	dir, base := filepath.Split(path)
-	ext := filepath.Ext(base)
-	name := strings.TrimSuffix(base, ext)
-	if ext == "" {
-		ext = ".vmd"
-	}
-	if name == "" {
-		return filepath.Join(dir, "_safe"+ext)
-	}
-	return filepath.Join(dir, name+"_safe"+ext)
-}
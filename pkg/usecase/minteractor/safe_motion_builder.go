@@ -1,17 +1,280 @@
 // 指示: miu200521358
 package minteractor
 
-import "github.com/miu200521358/mlib_go/pkg/domain/motion"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/model"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+)
+
+// StripKind は安全モーション保存で取り除く対象の種類を表す。
+type StripKind string
+
+// 取り除く対象の種類一覧。
+const (
+	StripBakeIk       StripKind = "bakeik"
+	StripIkFrames     StripKind = "ik"
+	StripCameraFrames StripKind = "camera"
+	StripLightFrames  StripKind = "light"
+	StripShadowFrames StripKind = "shadow"
+	StripAllMorphs    StripKind = "morphs"
+	StripAllBones     StripKind = "bones"
+	StripNames        StripKind = "names"
+	StripPattern      StripKind = "pattern"
+	StripNgTracks     StripKind = "ng"
+)
+
+// StripKinds は画面や設定で扱う種類の表示順。
+var StripKinds = []StripKind{
+	StripBakeIk,
+	StripIkFrames,
+	StripCameraFrames,
+	StripLightFrames,
+	StripShadowFrames,
+	StripAllMorphs,
+	StripAllBones,
+	StripNames,
+	StripPattern,
+	StripNgTracks,
+}
+
+// stripNameSeparator は設定文字列での名前の区切り文字。
+const stripNameSeparator = "|"
+
+// stripNoneValue は操作を1つも選択していないことを表す設定文字列。未保存と区別するために保存する。
+const stripNoneValue = "none"
+
+// stripEveryFrameValue はIKを全フレームに焼き込むことを表す設定文字列（「bakeik=every」）。
+const stripEveryFrameValue = "every"
+
+// StripOperation はモーションから取り除く操作の1つを表す。
+type StripOperation struct {
+	Kind StripKind
+	// Names は StripNames で取り除くボーン/モーフ名。
+	Names []string
+	// Pattern は StripPattern で取り除くボーン/モーフ名の正規表現。
+	Pattern string
+	// EveryFrame は StripBakeIk でキーフレームだけでなく全フレームに焼き込むかを表す。
+	// キーフレームだけの場合、キーの間の補間はIKの解と一致しない。
+	EveryFrame bool
+}
+
+// SanitizeContext はIKの焼き込みやNGトラックの除去など、モデルを必要とする操作の入力を表す。
+type SanitizeContext struct {
+	Model        *model.PmxModel
+	CheckOptions CheckOptions
+}
+
+// DefaultStripOperations は従来の安全モーション保存と同じ操作（IKフレームの除去）を返す。
+func DefaultStripOperations() []StripOperation {
+	return []StripOperation{{Kind: StripIkFrames}}
+}
 
 // BuildSafeMotion はIKフレームを空にしたモーションを複製する。
 func BuildSafeMotion(source *motion.VmdMotion) (*motion.VmdMotion, error) {
+	return BuildSanitizedMotion(source, DefaultStripOperations(), SanitizeContext{})
+}
+
+// BuildSanitizedMotion は指定した操作を順に適用したモーションを複製する。元のモーションは変更しない。
+func BuildSanitizedMotion(source *motion.VmdMotion, operations []StripOperation, sanitize SanitizeContext) (*motion.VmdMotion, error) {
+	sanitized, _, err := buildSanitizedMotion(source, operations, sanitize)
+	return sanitized, err
+}
+
+// buildSanitizedMotion は指定した操作を順に適用したモーションと、IKを焼き込んだ場合はその結果を返す。
+// IKの焼き込みはIKをOFFにするフレームを書き込むため、同時に指定したIKフレームの除去は行わない。
+func buildSanitizedMotion(source *motion.VmdMotion, operations []StripOperation, sanitize SanitizeContext) (*motion.VmdMotion, *IkBakeResult, error) {
 	if source == nil {
-		return nil, nil
+		return nil, nil, nil
 	}
 	copied, err := source.Copy()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	bakesIk := false
+	for _, operation := range operations {
+		if operation.Kind == StripBakeIk {
+			bakesIk = true
+		}
+	}
+	var bakeResult *IkBakeResult
+	for _, operation := range operations {
+		if bakesIk && operation.Kind == StripIkFrames {
+			continue
+		}
+		baked, err := applyStripOperation(&copied, operation, sanitize)
+		if err != nil {
+			return nil, nil, err
+		}
+		if baked != nil {
+			bakeResult = baked
+		}
+	}
+	return &copied, bakeResult, nil
+}
+
+// applyStripOperation は1つの操作をモーションに適用する。IKを焼き込んだ場合はその結果を返す。
+func applyStripOperation(motionData *motion.VmdMotion, operation StripOperation, sanitize SanitizeContext) (*IkBakeResult, error) {
+	switch operation.Kind {
+	case StripBakeIk:
+		if sanitize.Model == nil {
+			return nil, fmt.Errorf("IKの焼き込みにはモデルが必要です")
+		}
+		// 焼き込み中の書き込みが後続フレームの補間に影響しないよう、焼き込み前の状態から評価する。
+		snapshot, err := motionData.Copy()
+		if err != nil {
+			return nil, err
+		}
+		return bakeIkInto(sanitize.Model, &snapshot, motionData, IkBakeOptions{EveryFrame: operation.EveryFrame})
+	case StripIkFrames:
+		motionData.IkFrames = motion.NewIkFrames()
+	case StripCameraFrames:
+		motionData.CameraFrames = motion.NewCameraFrames()
+	case StripLightFrames:
+		motionData.LightFrames = motion.NewLightFrames()
+	case StripShadowFrames:
+		motionData.ShadowFrames = motion.NewShadowFrames()
+	case StripAllMorphs:
+		motionData.MorphFrames = motion.NewMorphFrames()
+	case StripAllBones:
+		motionData.BoneFrames = motion.NewBoneFrames()
+	case StripNames:
+		deleteTracks(motionData, operation.Names, operation.Names)
+	case StripPattern:
+		if operation.Pattern == "" {
+			return nil, nil
+		}
+		pattern, err := regexp.Compile(operation.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("除去する名前の正規表現が不正です: %w", err)
+		}
+		var boneNames, morphNames []string
+		if motionData.BoneFrames != nil {
+			boneNames = filterNames(motionData.BoneFrames.Names(), pattern)
+		}
+		if motionData.MorphFrames != nil {
+			morphNames = filterNames(motionData.MorphFrames.Names(), pattern)
+		}
+		deleteTracks(motionData, boneNames, morphNames)
+	case StripNgTracks:
+		if sanitize.Model == nil {
+			return nil, fmt.Errorf("NGトラックの除去にはモデルが必要です")
+		}
+		result, err := CheckExistsWithOptions(sanitize.Model, motionData, sanitize.CheckOptions)
+		if err != nil {
+			return nil, err
+		}
+		// モデル適合モーションと同じく、名前を書き換えるまで再生されないトラックも除く。
+		bones, morphs := UnplayableTracks(result)
+		deleteTracks(motionData, TrackNames(bones), TrackNames(morphs))
+	default:
+		return nil, fmt.Errorf("未対応の除去操作です: %s", operation.Kind)
+	}
+	return nil, nil
+}
+
+// filterNames は正規表現に一致する名前だけを返す。
+func filterNames(names []string, pattern *regexp.Regexp) []string {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		if pattern.MatchString(name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// deleteTracks は指定したボーン/モーフのトラックを削除する。
+func deleteTracks(motionData *motion.VmdMotion, boneNames []string, morphNames []string) {
+	if motionData.BoneFrames != nil {
+		for _, name := range boneNames {
+			if motionData.BoneFrames.Contains(name) {
+				motionData.BoneFrames.Delete(name)
+			}
+		}
+	}
+	if motionData.MorphFrames != nil {
+		for _, name := range morphNames {
+			if motionData.MorphFrames.Contains(name) {
+				motionData.MorphFrames.Delete(name)
+			}
+		}
+	}
+}
+
+// FormatStripOperations は操作一覧をユーザー設定に保存する文字列にする。
+// 名前指定は「names=名前1|名前2」、正規表現は「pattern=式」、全フレームの焼き込みは「bakeik=every」とし、
+// 操作がない場合は「none」とする。
+func FormatStripOperations(operations []StripOperation) []string {
+	if len(operations) == 0 {
+		return []string{stripNoneValue}
+	}
+	values := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		switch operation.Kind {
+		case StripNames:
+			values = append(values, string(operation.Kind)+"="+strings.Join(operation.Names, stripNameSeparator))
+		case StripPattern:
+			values = append(values, string(operation.Kind)+"="+operation.Pattern)
+		case StripBakeIk:
+			if operation.EveryFrame {
+				values = append(values, string(operation.Kind)+"="+stripEveryFrameValue)
+			} else {
+				values = append(values, string(operation.Kind))
+			}
+		default:
+			values = append(values, string(operation.Kind))
+		}
+	}
+	return values
+}
+
+// ParseStripOperations は FormatStripOperations で保存した文字列から操作一覧を復元する。
+// 「none」は操作を選択していない状態として空の一覧を返す。
+func ParseStripOperations(values []string) ([]StripOperation, error) {
+	operations := make([]StripOperation, 0, len(values))
+	if len(values) == 1 && values[0] == stripNoneValue {
+		return operations, nil
+	}
+	for _, value := range values {
+		kind, argument, _ := strings.Cut(value, "=")
+		operation := StripOperation{Kind: StripKind(kind)}
+		switch operation.Kind {
+		case StripNames:
+			operation.Names = SplitStripNames(argument)
+		case StripPattern:
+			operation.Pattern = argument
+		case StripBakeIk:
+			switch argument {
+			case "":
+			case stripEveryFrameValue:
+				operation.EveryFrame = true
+			default:
+				return nil, fmt.Errorf("未対応のIK焼き込みの設定です: %s", value)
+			}
+		case StripIkFrames, StripCameraFrames, StripLightFrames, StripShadowFrames,
+			StripAllMorphs, StripAllBones, StripNgTracks:
+		default:
+			return nil, fmt.Errorf("未対応の除去操作です: %s", kind)
+		}
+		operations = append(operations, operation)
+	}
+	return operations, nil
+}
+
+// SplitStripNames は「|」「,」「、」または改行で区切った名前一覧を分割する。
+func SplitStripNames(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == '|' || r == ',' || r == '、' || r == '\n' || r == '\r'
+	})
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if name := strings.TrimSpace(field); name != "" {
+			names = append(names, name)
+		}
 	}
-	copied.IkFrames = motion.NewIkFrames()
-	return &copied, nil
+	return names
 }
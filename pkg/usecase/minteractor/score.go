@@ -0,0 +1,252 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ImportanceTier はボーン/モーフの重要度区分を表す。
+type ImportanceTier string
+
+// 重要度区分一覧。
+const (
+	TierRoot   ImportanceTier = "root"
+	TierTrunk  ImportanceTier = "trunk"
+	TierLimb   ImportanceTier = "limb"
+	TierFinger ImportanceTier = "finger"
+	TierFace   ImportanceTier = "face"
+	TierOther  ImportanceTier = "other"
+)
+
+// importanceTiers は重み付けで指定できる重要度区分。
+var importanceTiers = map[ImportanceTier]struct{}{
+	TierRoot:   {},
+	TierTrunk:  {},
+	TierLimb:   {},
+	TierFinger: {},
+	TierFace:   {},
+	TierOther:  {},
+}
+
+// maxScoreContributors は減点要因として返す件数の上限。
+const maxScoreContributors = 5
+
+// ScoreWeights は互換スコアの重み付けを表す。
+type ScoreWeights struct {
+	// Tiers は重要度区分ごとの重み。
+	Tiers map[ImportanceTier]float64 `json:"tiers"`
+	// KeyCountFactor はキーフレーム数による重みの増分係数。0でキー数を無視する。
+	KeyCountFactor float64 `json:"keyCountFactor"`
+	// BoneTiers はボーン名ごとの重要度区分の上書き。
+	BoneTiers map[string]ImportanceTier `json:"boneTiers"`
+}
+
+// ScoreContributor は減点要因となったトラックを表す。
+type ScoreContributor struct {
+	Name string
+	Kind string
+	Tier ImportanceTier
+	// Penalty は減点幅（点）。
+	Penalty float64
+}
+
+// CompatibilityScore は0〜100の互換スコアと主な減点要因を表す。
+type CompatibilityScore struct {
+	Score        float64
+	Contributors []ScoreContributor
+}
+
+// スコア対象の種別。
+const (
+	ScoreKindBone  = "bone"
+	ScoreKindMorph = "morph"
+)
+
+// tierKeywords はボーン名（左右を除く）から重要度区分を推定するための語句。
+var tierKeywords = []struct {
+	Tier     ImportanceTier
+	Keywords []string
+}{
+	{Tier: TierFinger, Keywords: []string{"指"}},
+	{Tier: TierFace, Keywords: []string{"目", "眉", "舌", "歯", "顎", "あご"}},
+	{Tier: TierLimb, Keywords: []string{"肩", "腕", "ひじ", "手", "足", "ひざ", "つま先", "ik"}},
+	{Tier: TierTrunk, Keywords: []string{"上半身", "下半身", "腰", "首", "頭"}},
+}
+
+// rootBoneNames は最重要のボーン名。
+var rootBoneNames = map[string]struct{}{
+	"全ての親": {},
+	"センター": {},
+	"グルーブ": {},
+}
+
+// DefaultScoreWeights は既定の重み付けを返す。
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		Tiers: map[ImportanceTier]float64{
+			TierRoot:   10,
+			TierTrunk:  6,
+			TierLimb:   4,
+			TierFinger: 1,
+			TierFace:   2,
+			TierOther:  1,
+		},
+		KeyCountFactor: 0.5,
+	}
+}
+
+// LoadScoreWeights はJSONファイルから重み付けを読み込む。
+// 指定のない項目は既定の重み付けのまま使う。
+// 形式: {"tiers": {"root": 10, "finger": 2}, "keyCountFactor": 0.5, "boneTiers": {"左袖": "limb"}}
+func LoadScoreWeights(path string) (ScoreWeights, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ScoreWeights{}, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	weights := DefaultScoreWeights()
+	if err := json.Unmarshal(data, &weights); err != nil {
+		return ScoreWeights{}, err
+	}
+	if err := weights.validate(); err != nil {
+		return ScoreWeights{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return weights, nil
+}
+
+// validate は重み付けの区分名と値を検証する。
+func (w ScoreWeights) validate() error {
+	for tier, weight := range w.Tiers {
+		if _, ok := importanceTiers[tier]; !ok {
+			return fmt.Errorf("未対応の重要度区分です: %s", tier)
+		}
+		if weight < 0 || math.IsNaN(weight) {
+			return fmt.Errorf("重要度区分の重みが不正です: %s=%v", tier, weight)
+		}
+	}
+	for name, tier := range w.BoneTiers {
+		if _, ok := importanceTiers[tier]; !ok {
+			return fmt.Errorf("未対応の重要度区分です: %s=%s", name, tier)
+		}
+	}
+	if w.KeyCountFactor < 0 || math.IsNaN(w.KeyCountFactor) {
+		return fmt.Errorf("キーフレーム数の係数が不正です: %v", w.KeyCountFactor)
+	}
+	return nil
+}
+
+// ScoreCompatibility は判定結果から重み付きの互換スコアを算出する。
+// モデルでそのまま再生できるOKトラックの重みの割合を100点満点で表す。
+func ScoreCompatibility(result CheckResult, weights ScoreWeights) CompatibilityScore {
+	total := 0.0
+	penalties := make([]ScoreContributor, 0)
+	forEachScoredTrack(result, func(entry TrackEntry, kind string, ok bool) {
+		tier := weights.tierOf(entry.Name, kind)
+		weight := weights.weightOf(tier, entry.KeyCount)
+		total += weight
+		if ok {
+			return
+		}
+		penalties = append(penalties, ScoreContributor{
+			Name:    entry.Name,
+			Kind:    kind,
+			Tier:    tier,
+			Penalty: weight,
+		})
+	})
+
+	if total <= 0 {
+		return CompatibilityScore{Score: 100}
+	}
+	penaltyTotal := 0.0
+	for i := range penalties {
+		penalties[i].Penalty = penalties[i].Penalty * 100 / total
+		penaltyTotal += penalties[i].Penalty
+	}
+	sort.SliceStable(penalties, func(i, j int) bool {
+		if penalties[i].Penalty != penalties[j].Penalty {
+			return penalties[i].Penalty > penalties[j].Penalty
+		}
+		return penalties[i].Name < penalties[j].Name
+	})
+	if len(penalties) > maxScoreContributors {
+		penalties = penalties[:maxScoreContributors]
+	}
+	if len(penalties) == 0 {
+		penalties = nil
+	}
+	return CompatibilityScore{
+		Score:        math.Max(0, 100-penaltyTotal),
+		Contributors: penalties,
+	}
+}
+
+// forEachScoredTrack は互換スコアの対象トラックを、モデルでそのまま再生できるか（ok）と合わせて列挙する。
+func forEachScoredTrack(result CheckResult, fn func(entry TrackEntry, kind string, ok bool)) {
+	addTracks := func(entries []TrackEntry, kind string, ok bool) {
+		for _, entry := range entries {
+			fn(entry, kind, ok)
+		}
+	}
+	addMapped := func(entries []MappedName, kind string, ok bool) {
+		for _, entry := range entries {
+			fn(entry.TrackEntry, kind, ok)
+		}
+	}
+	addAmbiguous := func(entries []AmbiguousName, kind string) {
+		for _, entry := range entries {
+			fn(entry.TrackEntry, kind, false)
+		}
+	}
+	addTracks(result.OkBones, ScoreKindBone, true)
+	addTracks(result.OkMorphs, ScoreKindMorph, true)
+	addTracks(result.NgBones, ScoreKindBone, false)
+	addTracks(result.NgMorphs, ScoreKindMorph, false)
+	// 対応付けのみのトラックは名前を書き換えるまで再生されないため減点する。
+	addMapped(result.MappedBones, ScoreKindBone, false)
+	addMapped(result.MappedMorphs, ScoreKindMorph, false)
+	addMapped(result.EnglishBones, ScoreKindBone, false)
+	addMapped(result.EnglishMorphs, ScoreKindMorph, false)
+	// 切り詰め一致はMMDでそのまま再生されるが、曖昧な場合はどれに当たるか保証できない。
+	addMapped(result.TruncatedBones, ScoreKindBone, true)
+	addMapped(result.TruncatedMorphs, ScoreKindMorph, true)
+	addAmbiguous(result.AmbiguousBones, ScoreKindBone)
+	addAmbiguous(result.AmbiguousMorphs, ScoreKindMorph)
+}
+
+// tierOf はトラック名から重要度区分を判定する。
+func (w ScoreWeights) tierOf(name string, kind string) ImportanceTier {
+	if kind == ScoreKindMorph {
+		return TierFace
+	}
+	if tier, ok := w.BoneTiers[name]; ok {
+		return tier
+	}
+	if _, ok := rootBoneNames[name]; ok {
+		return TierRoot
+	}
+	core := normalizeName(name).Core
+	for _, group := range tierKeywords {
+		for _, keyword := range group.Keywords {
+			if strings.Contains(core, keyword) {
+				return group.Tier
+			}
+		}
+	}
+	return TierOther
+}
+
+// weightOf は重要度区分とキーフレーム数から重みを算出する。
+func (w ScoreWeights) weightOf(tier ImportanceTier, keyCount int) float64 {
+	weight, ok := w.Tiers[tier]
+	if !ok {
+		weight = 1
+	}
+	return weight * (1 + w.KeyCountFactor*math.Log10(1+float64(keyCount)))
+}
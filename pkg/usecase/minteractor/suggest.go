@@ -0,0 +1,246 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/model"
+)
+
+// maxSuggestionCount はNG名1件あたりの候補数上限。
+const maxSuggestionCount = 3
+
+// NameSuggestion はNG名に対するモデル内の候補名を表す。
+type NameSuggestion struct {
+	Name     string
+	Index    int
+	Distance int
+}
+
+// normalizedName は候補照合用に正規化した名前を表す。
+type normalizedName struct {
+	Side rune
+	Core string
+}
+
+// suggestionTarget は照合対象のモデル側名称を表す。
+type suggestionTarget struct {
+	Name       string
+	Index      int
+	Normalized normalizedName
+}
+
+// halfKanaTable は半角カナから全角カナへの変換表。
+var halfKanaTable = map[rune]rune{
+	'｡': '。', '｢': '「', '｣': '」', '､': '、', '･': '・', 'ｦ': 'ヲ',
+	'ｧ': 'ァ', 'ｨ': 'ィ', 'ｩ': 'ゥ', 'ｪ': 'ェ', 'ｫ': 'ォ', 'ｬ': 'ャ', 'ｭ': 'ュ', 'ｮ': 'ョ', 'ｯ': 'ッ', 'ｰ': 'ー',
+	'ｱ': 'ア', 'ｲ': 'イ', 'ｳ': 'ウ', 'ｴ': 'エ', 'ｵ': 'オ',
+	'ｶ': 'カ', 'ｷ': 'キ', 'ｸ': 'ク', 'ｹ': 'ケ', 'ｺ': 'コ',
+	'ｻ': 'サ', 'ｼ': 'シ', 'ｽ': 'ス', 'ｾ': 'セ', 'ｿ': 'ソ',
+	'ﾀ': 'タ', 'ﾁ': 'チ', 'ﾂ': 'ツ', 'ﾃ': 'テ', 'ﾄ': 'ト',
+	'ﾅ': 'ナ', 'ﾆ': 'ニ', 'ﾇ': 'ヌ', 'ﾈ': 'ネ', 'ﾉ': 'ノ',
+	'ﾊ': 'ハ', 'ﾋ': 'ヒ', 'ﾌ': 'フ', 'ﾍ': 'ヘ', 'ﾎ': 'ホ',
+	'ﾏ': 'マ', 'ﾐ': 'ミ', 'ﾑ': 'ム', 'ﾒ': 'メ', 'ﾓ': 'モ',
+	'ﾔ': 'ヤ', 'ﾕ': 'ユ', 'ﾖ': 'ヨ',
+	'ﾗ': 'ラ', 'ﾘ': 'リ', 'ﾙ': 'ル', 'ﾚ': 'レ', 'ﾛ': 'ロ',
+	'ﾜ': 'ワ', 'ﾝ': 'ン',
+}
+
+// SuggestBoneNames はNGボーン名ごとにモデル内の候補ボーン名を返す。
+func SuggestBoneNames(modelData *model.PmxModel, names []string) map[string][]NameSuggestion {
+	if modelData == nil || modelData.Bones == nil || len(names) == 0 {
+		return nil
+	}
+	bones := modelData.Bones.Values()
+	targets := make([]suggestionTarget, 0, len(bones))
+	for _, bone := range bones {
+		if bone == nil {
+			continue
+		}
+		targets = append(targets, suggestionTarget{
+			Name:       bone.Name(),
+			Index:      bone.Index(),
+			Normalized: normalizeName(bone.Name()),
+		})
+	}
+	return suggestNames(targets, names)
+}
+
+// SuggestMorphNames はNGモーフ名ごとにモデル内の候補モーフ名を返す。
+func SuggestMorphNames(modelData *model.PmxModel, names []string) map[string][]NameSuggestion {
+	if modelData == nil || modelData.Morphs == nil || len(names) == 0 {
+		return nil
+	}
+	morphs := modelData.Morphs.Values()
+	targets := make([]suggestionTarget, 0, len(morphs))
+	for _, morph := range morphs {
+		if morph == nil {
+			continue
+		}
+		targets = append(targets, suggestionTarget{
+			Name:       morph.Name(),
+			Index:      morph.Index(),
+			Normalized: normalizeName(morph.Name()),
+		})
+	}
+	return suggestNames(targets, names)
+}
+
+// suggestNames は正規化後の編集距離で候補を順位付けする。
+func suggestNames(targets []suggestionTarget, names []string) map[string][]NameSuggestion {
+	if len(targets) == 0 {
+		return nil
+	}
+	out := make(map[string][]NameSuggestion, len(names))
+	for _, name := range names {
+		source := normalizeName(name)
+		limit := max(1, utf8.RuneCountInString(source.Core)/2)
+		candidates := make([]NameSuggestion, 0, maxSuggestionCount)
+		for _, target := range targets {
+			if target.Normalized.Side != source.Side {
+				// 左右違いは別ボーンのため候補にしない。
+				continue
+			}
+			distance := levenshtein(source.Core, target.Normalized.Core)
+			if distance > limit {
+				continue
+			}
+			candidates = append(candidates, NameSuggestion{
+				Name:     target.Name,
+				Index:    target.Index,
+				Distance: distance,
+			})
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].Distance != candidates[j].Distance {
+				return candidates[i].Distance < candidates[j].Distance
+			}
+			return candidates[i].Index < candidates[j].Index
+		})
+		if len(candidates) > maxSuggestionCount {
+			candidates = candidates[:maxSuggestionCount]
+		}
+		out[name] = candidates
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// TopSuggestion は最有力候補を返す。
+func TopSuggestion(suggestions map[string][]NameSuggestion, name string) (NameSuggestion, bool) {
+	candidates := suggestions[name]
+	if len(candidates) == 0 {
+		return NameSuggestion{}, false
+	}
+	return candidates[0], true
+}
+
+// normalizeName は全角/半角・左右表記・IK表記を揃えた名前を返す。
+func normalizeName(name string) normalizedName {
+	var builder strings.Builder
+	builder.Grow(len(name))
+	runes := []rune(name)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '　' || r == ' ' || r == '\t':
+			continue
+		case r >= '！' && r <= '～':
+			// 全角英数記号を半角に揃える。
+			r -= 0xFEE0
+		case r >= '｡' && r <= 'ﾟ':
+			r = convertHalfKana(runes, &i)
+		}
+		if r >= 'A' && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		builder.WriteRune(r)
+	}
+	return splitSide(builder.String())
+}
+
+// convertHalfKana は半角カナを全角カナへ変換し、濁点/半濁点を結合する。
+func convertHalfKana(runes []rune, index *int) rune {
+	r := runes[*index]
+	full, ok := halfKanaTable[r]
+	if !ok {
+		return r
+	}
+	if *index+1 >= len(runes) {
+		return full
+	}
+	switch runes[*index+1] {
+	case 'ﾞ':
+		if full == 'ウ' {
+			*index++
+			return 'ヴ'
+		}
+		if (full >= 'カ' && full <= 'ト' && full != 'ッ') || (full >= 'ハ' && full <= 'ホ') {
+			*index++
+			return full + 1
+		}
+	case 'ﾟ':
+		if full >= 'ハ' && full <= 'ホ' {
+			*index++
+			return full + 2
+		}
+	}
+	return full
+}
+
+// splitSide は左右表記を取り出して本体名と分離する。
+func splitSide(name string) normalizedName {
+	switch {
+	case strings.HasPrefix(name, "左"):
+		return normalizedName{Side: '左', Core: strings.TrimPrefix(name, "左")}
+	case strings.HasPrefix(name, "右"):
+		return normalizedName{Side: '右', Core: strings.TrimPrefix(name, "右")}
+	}
+	for _, suffix := range []string{".l", "_l"} {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return normalizedName{Side: '左', Core: strings.TrimSuffix(name, suffix)}
+		}
+	}
+	for _, suffix := range []string{".r", "_r"} {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return normalizedName{Side: '右', Core: strings.TrimSuffix(name, suffix)}
+		}
+	}
+	return normalizedName{Core: name}
+}
+
+// levenshtein は文字単位の編集距離を返す。
+func levenshtein(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
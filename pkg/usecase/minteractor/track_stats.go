@@ -0,0 +1,110 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"math"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/mmath"
+	"github.com/miu200521358/mlib_go/pkg/domain/motion"
+)
+
+// constantTolerance は値が変化していないとみなす許容誤差。
+const constantTolerance = 1e-6
+
+// collectBoneStats はボーントラックのキーフレーム統計を集計する。
+func collectBoneStats(frames *motion.BoneNameFrames) TrackStats {
+	stats := TrackStats{Constant: true}
+	if frames == nil {
+		return stats
+	}
+	var first *motion.BoneFrame
+	frames.ForEach(func(frame motion.Frame, bf *motion.BoneFrame) bool {
+		if bf == nil {
+			return true
+		}
+		stats.addFrame(frame)
+		if first == nil {
+			first = bf
+			return true
+		}
+		if stats.Constant && !sameBoneValue(first, bf) {
+			stats.Constant = false
+		}
+		return true
+	})
+	return stats
+}
+
+// collectMorphStats はモーフトラックのキーフレーム統計を集計する。
+func collectMorphStats(frames *motion.MorphNameFrames) TrackStats {
+	stats := TrackStats{Constant: true}
+	if frames == nil {
+		return stats
+	}
+	var first *motion.MorphFrame
+	frames.ForEach(func(frame motion.Frame, mf *motion.MorphFrame) bool {
+		if mf == nil {
+			return true
+		}
+		stats.addFrame(frame)
+		if first == nil {
+			first = mf
+			return true
+		}
+		if stats.Constant && math.Abs(first.Ratio-mf.Ratio) > constantTolerance {
+			stats.Constant = false
+		}
+		return true
+	})
+	return stats
+}
+
+// addFrame はキーフレーム数と先頭/末尾フレームを更新する。
+func (s *TrackStats) addFrame(frame motion.Frame) {
+	if s.KeyCount == 0 || frame < s.FirstFrame {
+		s.FirstFrame = frame
+	}
+	if s.KeyCount == 0 || frame > s.LastFrame {
+		s.LastFrame = frame
+	}
+	s.KeyCount++
+}
+
+// sameBoneValue は2つのボーンキーフレームの移動/回転が等しいか判定する。
+func sameBoneValue(a, b *motion.BoneFrame) bool {
+	return sameVec3(a.Position, b.Position) && sameQuaternion(a.Rotation, b.Rotation)
+}
+
+// sameVec3 は未設定を原点とみなしてベクトルを比較する。
+func sameVec3(a, b *mmath.Vec3) bool {
+	ax, ay, az := vec3Values(a)
+	bx, by, bz := vec3Values(b)
+	return math.Abs(ax-bx) <= constantTolerance &&
+		math.Abs(ay-by) <= constantTolerance &&
+		math.Abs(az-bz) <= constantTolerance
+}
+
+// sameQuaternion は未設定を単位回転とみなしてクォータニオンを比較する。
+func sameQuaternion(a, b *mmath.Quaternion) bool {
+	ax, ay, az, aw := quaternionValues(a)
+	bx, by, bz, bw := quaternionValues(b)
+	// q と -q は同じ回転を表す。
+	dot := ax*bx + ay*by + az*bz + aw*bw
+	return math.Abs(math.Abs(dot)-1) <= constantTolerance
+}
+
+// vec3Values はベクトルの成分を返す。
+func vec3Values(v *mmath.Vec3) (float64, float64, float64) {
+	if v == nil {
+		return 0, 0, 0
+	}
+	return v.X, v.Y, v.Z
+}
+
+// quaternionValues はクォータニオンの成分を返す。
+func quaternionValues(q *mmath.Quaternion) (float64, float64, float64, float64) {
+	if q == nil {
+		return 0, 0, 0, 1
+	}
+	return q.X(), q.Y(), q.Z(), q.W()
+}
@@ -0,0 +1,109 @@
+// 指示: miu200521358
+package minteractor
+
+import (
+	"bytes"
+
+	"github.com/miu200521358/mlib_go/pkg/domain/model"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// vmdNameByteLength はVMDに保存されるボーン/モーフ名のShift-JISでのバイト数。
+const vmdNameByteLength = 15
+
+// AmbiguousName は切り詰められた名前が複数のモデル側の名前に前方一致したものを表す。
+type AmbiguousName struct {
+	TrackEntry
+	Candidates []string
+}
+
+// sjisName はShift-JISに変換したモデル側の名前を表す。
+type sjisName struct {
+	Name  string
+	Bytes []byte
+}
+
+// truncationIndex はVMDの名前欄に収まらないモデル側の名前の一覧を表す。
+type truncationIndex []sjisName
+
+// buildBoneTruncationIndex はVMDで切り詰められるボーン名の一覧を作る。
+func buildBoneTruncationIndex(modelData *model.PmxModel) truncationIndex {
+	if modelData == nil || modelData.Bones == nil {
+		return nil
+	}
+	names := make([]string, 0, modelData.Bones.Len())
+	for _, bone := range modelData.Bones.Values() {
+		if bone != nil {
+			names = append(names, bone.Name())
+		}
+	}
+	return newTruncationIndex(names)
+}
+
+// buildMorphTruncationIndex はVMDで切り詰められるモーフ名の一覧を作る。
+func buildMorphTruncationIndex(modelData *model.PmxModel) truncationIndex {
+	if modelData == nil || modelData.Morphs == nil {
+		return nil
+	}
+	names := make([]string, 0, modelData.Morphs.Len())
+	for _, morph := range modelData.Morphs.Values() {
+		if morph != nil {
+			names = append(names, morph.Name())
+		}
+	}
+	return newTruncationIndex(names)
+}
+
+// newTruncationIndex はShift-JISで名前欄を超える名前だけを集める。
+func newTruncationIndex(names []string) truncationIndex {
+	index := make(truncationIndex, 0)
+	for _, name := range names {
+		encoded, ok := encodeShiftJIS(name)
+		if !ok || len(encoded) <= vmdNameByteLength {
+			continue
+		}
+		index = append(index, sjisName{Name: name, Bytes: encoded})
+	}
+	return index
+}
+
+// match はモーション側の名前が切り詰め後の名前として一致するモデル側の名前を返す。
+// 名前欄を使い切っていない名前は切り詰められていないため対象外とする。
+func (index truncationIndex) match(name string) []string {
+	if len(index) == 0 {
+		return nil
+	}
+	encoded, ok := encodeShiftJIS(name)
+	if !ok || len(encoded) < vmdNameByteLength-1 || len(encoded) > vmdNameByteLength {
+		return nil
+	}
+	matches := make([]string, 0, 1)
+	for _, candidate := range index {
+		if !bytes.HasPrefix(candidate.Bytes, encoded) {
+			continue
+		}
+		// 14バイトの場合は、次の2バイト文字が入り切らずに落ちたときだけ切り詰めとみなす。
+		if len(encoded) < vmdNameByteLength && !isShiftJISLeadByte(candidate.Bytes[len(encoded)]) {
+			continue
+		}
+		matches = append(matches, candidate.Name)
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches
+}
+
+// encodeShiftJIS は名前をShift-JISに変換する。変換できない文字を含む場合は false を返す。
+func encodeShiftJIS(name string) ([]byte, bool) {
+	encoded, err := japanese.ShiftJIS.NewEncoder().Bytes([]byte(name))
+	if err != nil {
+		return nil, false
+	}
+	return encoded, true
+}
+
+// isShiftJISLeadByte はShift-JISの2バイト文字の1バイト目か判定する。
+func isShiftJISLeadByte(b byte) bool {
+	return (b >= 0x81 && b <= 0x9f) || (b >= 0xe0 && b <= 0xfc)
+}